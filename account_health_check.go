@@ -0,0 +1,389 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+/*
+
+Implementation for sophos central ACCOUNT HEALTH CHECK API
+https://developer.sophos.com/docs/account-health-check-v1/1/overview
+
+GET		/health-check
+
+The check has four sections: protection installed, policy, exclusions and tamper protection.
+Each part of a section is scored from 0 to 100 and lists what made it lose points.  Snoozed
+checks have been silenced by an admin and are still reported.
+*/
+
+// GetAccountHealthCheck runs the account health check of a tenant.
+// Allowed query params: checks
+func (c *Client) GetAccountHealthCheck(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (AccountHealthCheck, error) {
+	// https://api-{dataRegion}.central.sophos.com/account-health-check/v1/health-check
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/account-health-check/v1/health-check", queryParams, nil)
+	if err != nil {
+		return AccountHealthCheck{}, err
+	}
+
+	return UnmarshalAccountHealthCheck(b)
+}
+
+// GetPartnerHealthCheck runs the account health check of every tenant and ranks them, lowest
+// score first.  Tenants whose check failed are listed last with the error.
+func (c *Client) GetPartnerHealthCheck(ctx context.Context, tenants []TenantsResponseItem) HealthCheckReport {
+
+	checks := make(map[string]AccountHealthCheck, len(tenants))
+	errs := eachTenant(ctx, tenants, func(tenant TenantsResponseItem) error {
+		hc, err := c.GetAccountHealthCheck(ctx, tenant, nil)
+		if err != nil {
+			return err
+		}
+		checks[tenant.ID] = hc
+		return nil
+	})
+
+	var report HealthCheckReport
+	for _, tenant := range tenants {
+		row := HealthCheckRow{Tenant: tenant, Err: errs[tenant.ID]}
+		if row.Err == nil {
+			hc := checks[tenant.ID]
+			row.Check = hc
+			row.Score = hc.Score()
+			row.Failures = len(hc.Failures())
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	report.rank()
+
+	return report
+}
+
+// Score is the overall score of the check, the mean of the section scores.  Sections with
+// nothing to check, such as policies on an account with no policies, are left out.
+func (hc AccountHealthCheck) Score() int {
+	e := hc.Endpoint
+	var sum, n int
+	for _, section := range []scored{e.Protection.score(), e.Policy.score(), e.Exclusions.score(), e.TamperProtection.score()} {
+		if section.ok {
+			sum += section.score
+			n++
+		}
+	}
+	if n == 0 {
+		return 100
+	}
+	return sum / n
+}
+
+// Score is the lower of the computer and server scores.
+func (p HealthCheckProtection) Score() int {
+	return p.score().value()
+}
+
+func (p HealthCheckProtection) score() scored {
+	var s scored
+	for _, part := range []*HealthCheckProtectionPart{p.Computer, p.Server} {
+		if part != nil && part.Total > 0 {
+			s.add(part.Score)
+		}
+	}
+	return s
+}
+
+// Score is the lowest score of any policy type.
+func (p HealthCheckPolicy) Score() int {
+	return p.score().value()
+}
+
+func (p HealthCheckPolicy) score() scored {
+	var s scored
+	for _, parts := range []map[string]HealthCheckPolicyPart{p.Computer, p.Server} {
+		for _, part := range parts {
+			if part.Total > 0 {
+				s.add(part.Score)
+			}
+		}
+	}
+	return s
+}
+
+// Score is the lowest of the computer, server and global exclusion scores.
+func (e HealthCheckExclusions) Score() int {
+	return e.score().value()
+}
+
+func (e HealthCheckExclusions) score() scored {
+	var s scored
+	for _, part := range []*HealthCheckExclusionPart{e.Policy.Computer, e.Policy.Server, e.Global} {
+		if part != nil {
+			s.add(part.Score)
+		}
+	}
+	return s
+}
+
+// Score is the lowest of the computer, server and global tamper protection scores.
+func (t HealthCheckTamperProtection) Score() int {
+	return t.score().value()
+}
+
+func (t HealthCheckTamperProtection) score() scored {
+	var s scored
+	for _, part := range []*HealthCheckTamperPart{t.Computer, t.Server} {
+		if part != nil && part.Total > 0 {
+			s.add(part.Score)
+		}
+	}
+	if t.Global != nil {
+		s.add(t.Global.Score)
+	}
+	return s
+}
+
+// Failures lists everything the check found wrong, section by section.
+func (hc AccountHealthCheck) Failures() []HealthCheckFailure {
+	var fs []HealthCheckFailure
+	e := hc.Endpoint
+
+	for _, part := range []struct {
+		name string
+		c    *HealthCheckProtectionPart
+	}{{"computer", e.Protection.Computer}, {"server", e.Protection.Server}} {
+		if part.c == nil {
+			continue
+		}
+		for _, ep := range part.c.NotFullyProtectedItems {
+			fs = append(fs, HealthCheckFailure{Section: HealthCheckProtectionSection, Part: part.name, ID: ep.ID, Detail: ep.Name})
+		}
+	}
+
+	for _, part := range []struct {
+		name  string
+		types map[string]HealthCheckPolicyPart
+	}{{"computer", e.Policy.Computer}, {"server", e.Policy.Server}} {
+		names := make([]string, 0, len(part.types))
+		for name := range part.types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, policyType := range names {
+			for _, p := range part.types[policyType].NotOnRecommendedItems {
+				fs = append(fs, HealthCheckFailure{Section: HealthCheckPolicySection, Part: part.name + " " + policyType, ID: p.ID, Detail: p.Name})
+			}
+		}
+	}
+
+	for _, part := range []struct {
+		name string
+		x    *HealthCheckExclusionPart
+	}{{"computer", e.Exclusions.Policy.Computer}, {"server", e.Exclusions.Policy.Server}, {"global", e.Exclusions.Global}} {
+		if part.x == nil {
+			continue
+		}
+		for _, x := range part.x.ScanExclusions {
+			fs = append(fs, HealthCheckFailure{Section: HealthCheckExclusionsSection, Part: part.name, Detail: fmt.Sprintf("%s: %s", x.Type, x.Value)})
+		}
+	}
+
+	for _, part := range []struct {
+		name string
+		t    *HealthCheckTamperPart
+	}{{"computer", e.TamperProtection.Computer}, {"server", e.TamperProtection.Server}} {
+		if part.t == nil {
+			continue
+		}
+		for _, ep := range part.t.DisabledItems {
+			fs = append(fs, HealthCheckFailure{Section: HealthCheckTamperSection, Part: part.name, ID: ep.ID, Detail: ep.Name})
+		}
+	}
+	if g := e.TamperProtection.Global; g != nil && !g.Enabled {
+		fs = append(fs, HealthCheckFailure{Section: HealthCheckTamperSection, Part: "global", Detail: "tamper protection is turned off for the account"})
+	}
+
+	return fs
+}
+
+// WriteTable writes the report as an aligned text table, one tenant a line in rank order.
+func (r HealthCheckReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tTENANT\tSCORE\tPROTECTION\tPOLICY\tEXCLUSIONS\tTAMPER\tFAILURES")
+	for i, row := range r.Rows {
+		if row.Err != nil {
+			fmt.Fprintf(tw, "-\t%s\t-\t-\t-\t-\t-\terror: %v\n", row.Tenant.Name, row.Err)
+			continue
+		}
+		e := row.Check.Endpoint
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\n", i+1, row.Tenant.Name, row.Score,
+			e.Protection.Score(), e.Policy.Score(), e.Exclusions.Score(), e.TamperProtection.Score(), row.Failures)
+	}
+	return tw.Flush()
+}
+
+// rank orders the rows by score, lowest first, with failed checks last.  Ties keep the order
+// the tenants were given in.
+func (r *HealthCheckReport) rank() {
+	sort.SliceStable(r.Rows, func(i, j int) bool {
+		a, b := r.Rows[i], r.Rows[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		return a.Score < b.Score
+	})
+}
+
+// scored is the lowest score of the parts of a section that were checked.  ok is false when
+// none were.
+type scored struct {
+	score int
+	ok    bool
+}
+
+func (s *scored) add(score int) {
+	if !s.ok || score < s.score {
+		s.score = score
+	}
+	s.ok = true
+}
+
+// value is the score, or 100 when nothing was checked.
+func (s scored) value() int {
+	if !s.ok {
+		return 100
+	}
+	return s.score
+}
+
+func UnmarshalAccountHealthCheck(data []byte) (AccountHealthCheck, error) {
+	var r AccountHealthCheck
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return AccountHealthCheck{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type AccountHealthCheck struct {
+	Endpoint HealthCheckEndpoint `json:"endpoint"`
+}
+
+type HealthCheckEndpoint struct {
+	Protection       HealthCheckProtection       `json:"protection"`
+	Policy           HealthCheckPolicy           `json:"policy"`
+	Exclusions       HealthCheckExclusions       `json:"exclusions"`
+	TamperProtection HealthCheckTamperProtection `json:"tamperProtection"`
+}
+
+// HealthCheckProtection checks that endpoints have every protection component installed.  A
+// part is nil when the check left it out, for example an account with no servers.
+type HealthCheckProtection struct {
+	Computer *HealthCheckProtectionPart `json:"computer,omitempty"`
+	Server   *HealthCheckProtectionPart `json:"server,omitempty"`
+}
+
+type HealthCheckProtectionPart struct {
+	Total                  int                  `json:"total"`
+	NotFullyProtected      int                  `json:"notFullyProtected"`
+	NotFullyProtectedItems []HealthCheckItemRef `json:"notFullyProtectedItems,omitempty"`
+	Score                  int                  `json:"score"`
+	Snoozed                bool                 `json:"snoozed"`
+}
+
+// HealthCheckPolicy checks that policies are on Sophos' recommended settings.  The parts are
+// keyed by policy type, such as threat-protection.
+type HealthCheckPolicy struct {
+	Computer map[string]HealthCheckPolicyPart `json:"computer"`
+	Server   map[string]HealthCheckPolicyPart `json:"server"`
+}
+
+type HealthCheckPolicyPart struct {
+	Total                 int                  `json:"total"`
+	NotOnRecommended      int                  `json:"notOnRecommended"`
+	NotOnRecommendedItems []HealthCheckItemRef `json:"notOnRecommendedItems,omitempty"`
+	Score                 int                  `json:"score"`
+	Snoozed               bool                 `json:"snoozed"`
+}
+
+// HealthCheckExclusions checks for scan exclusions that are a security risk, in policies and
+// in the global exclusions.
+type HealthCheckExclusions struct {
+	Policy struct {
+		Computer *HealthCheckExclusionPart `json:"computer,omitempty"`
+		Server   *HealthCheckExclusionPart `json:"server,omitempty"`
+	} `json:"policy"`
+	Global *HealthCheckExclusionPart `json:"global,omitempty"`
+}
+
+type HealthCheckExclusionPart struct {
+	NumberOfSecurityRisks int                    `json:"numberOfSecurityRisks"`
+	ScanExclusions        []HealthCheckExclusion `json:"scanExclusions,omitempty"`
+	Score                 int                    `json:"score"`
+	Snoozed               bool                   `json:"snoozed"`
+}
+
+type HealthCheckExclusion struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// HealthCheckTamperProtection checks tamper protection is on for the account and every endpoint.
+type HealthCheckTamperProtection struct {
+	Computer *HealthCheckTamperPart   `json:"computer,omitempty"`
+	Server   *HealthCheckTamperPart   `json:"server,omitempty"`
+	Global   *HealthCheckTamperGlobal `json:"globalDetail,omitempty"`
+}
+
+type HealthCheckTamperPart struct {
+	Total         int                  `json:"total"`
+	Disabled      int                  `json:"disabled"`
+	DisabledItems []HealthCheckItemRef `json:"disabledItems,omitempty"`
+	Score         int                  `json:"score"`
+	Snoozed       bool                 `json:"snoozed"`
+}
+
+type HealthCheckTamperGlobal struct {
+	Enabled bool `json:"enabled"`
+	Score   int  `json:"score"`
+	Snoozed bool `json:"snoozed"`
+}
+
+// HealthCheckItemRef is an endpoint or policy the check found wrong.
+type HealthCheckItemRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type HealthCheckSection string
+
+const (
+	HealthCheckProtectionSection HealthCheckSection = "protection"
+	HealthCheckPolicySection     HealthCheckSection = "policy"
+	HealthCheckExclusionsSection HealthCheckSection = "exclusions"
+	HealthCheckTamperSection     HealthCheckSection = "tamperProtection"
+)
+
+// HealthCheckFailure is one thing the check found wrong.  ID is the endpoint or policy, where
+// there is one.
+type HealthCheckFailure struct {
+	Section HealthCheckSection
+	Part    string
+	ID      string
+	Detail  string
+}
+
+type HealthCheckReport struct {
+	Rows []HealthCheckRow
+}
+
+type HealthCheckRow struct {
+	Tenant   TenantsResponseItem
+	Score    int
+	Failures int
+	Check    AccountHealthCheck
+	Err      error
+}
@@ -0,0 +1,118 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+const healthCheckBody = `{"endpoint": {
+	"protection": {
+		"computer": {"total": 10, "notFullyProtected": 1, "notFullyProtectedItems": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "WIN10-01"}], "score": 90, "snoozed": false},
+		"server": {"total": 2, "notFullyProtected": 0, "score": 100, "snoozed": false}
+	},
+	"policy": {
+		"computer": {"threat-protection": {"total": 3, "notOnRecommended": 1, "notOnRecommendedItems": [{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "name": "Legacy"}], "score": 70, "snoozed": false}},
+		"server": {"server-threat-protection": {"total": 1, "notOnRecommended": 0, "score": 100, "snoozed": false}}
+	},
+	"exclusions": {
+		"policy": {
+			"computer": {"numberOfSecurityRisks": 1, "scanExclusions": [{"type": "path", "value": "C:\\"}], "score": 80, "snoozed": false},
+			"server": {"numberOfSecurityRisks": 0, "score": 100, "snoozed": false}
+		},
+		"global": {"numberOfSecurityRisks": 0, "score": 100, "snoozed": false}
+	},
+	"tamperProtection": {
+		"computer": {"total": 10, "disabled": 0, "score": 100, "snoozed": false},
+		"server": {"total": 2, "disabled": 0, "score": 100, "snoozed": false},
+		"globalDetail": {"enabled": true, "score": 100, "snoozed": false}
+	}
+}}`
+
+func TestAccountHealthCheck_Failures(t *testing.T) {
+	a := assert.New(t)
+
+	hc, err := UnmarshalAccountHealthCheck([]byte(healthCheckBody))
+	a.NoError(err)
+	a.Equal(90, hc.Endpoint.Protection.Score())
+	a.Equal(70, hc.Endpoint.Policy.Score())
+	a.Equal(80, hc.Endpoint.Exclusions.Score())
+	a.Equal(100, hc.Endpoint.TamperProtection.Score())
+	a.Equal(85, hc.Score())
+
+	a.Equal([]HealthCheckFailure{
+		{Section: HealthCheckProtectionSection, Part: "computer", ID: "bc893b97-86a8-41aa-b65c-910e11505605", Detail: "WIN10-01"},
+		{Section: HealthCheckPolicySection, Part: "computer threat-protection", ID: "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", Detail: "Legacy"},
+		{Section: HealthCheckExclusionsSection, Part: "computer", Detail: `path: C:\`},
+	}, hc.Failures())
+}
+
+func TestAccountHealthCheck_Score_noServers(t *testing.T) {
+	a := assert.New(t)
+
+	hc, err := UnmarshalAccountHealthCheck([]byte(`{"endpoint": {
+		"protection": {"computer": {"total": 4, "notFullyProtected": 0, "score": 100}, "server": {"total": 0, "notFullyProtected": 0, "score": 0}},
+		"policy": {"computer": {"threat-protection": {"total": 1, "notOnRecommended": 0, "score": 100}}, "server": {"server-threat-protection": {"total": 0, "score": 0}}},
+		"exclusions": {"policy": {"computer": {"numberOfSecurityRisks": 0, "score": 90}}},
+		"tamperProtection": {"computer": {"total": 4, "disabled": 0, "score": 100}}
+	}}`))
+	a.NoError(err)
+	a.Equal(100, hc.Endpoint.Protection.Score())
+	a.Equal(100, hc.Endpoint.Policy.Score())
+	a.Equal(90, hc.Endpoint.Exclusions.Score())
+	a.Equal(100, hc.Endpoint.TamperProtection.Score())
+	a.Equal(97, hc.Score())
+	a.Empty(hc.Failures())
+
+	a.Equal(100, AccountHealthCheck{}.Score())
+}
+
+func TestClient_GetPartnerHealthCheck(t *testing.T) {
+	a := assert.New(t)
+
+	healthy := strings.NewReplacer(`"score": 90`, `"score": 100`, `"score": 70`, `"score": 100`, `"score": 80`, `"score": 100`).Replace(healthCheckBody)
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/account-health-check/v1/health-check", req.URL.Path)
+			switch req.Header.Get("X-Tenant-ID") {
+			case "bc893b97-86a8-41aa-b65c-910e11505605":
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(healthy))}
+			case "d2ba043d-7fcd-4158-a861-1ec2c01f3d14":
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(healthCheckBody))}
+			}
+			return &http.Response{StatusCode: 403, Body: ioutil.NopCloser(bytes.NewBufferString(`{"error": "forbidden"}`))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	tenants := []TenantsResponseItem{
+		{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Name: "Healthy", ApiHost: "https://api-us03.central.sophos.com"},
+		{ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504", Name: "Forbidden", ApiHost: "https://api-us03.central.sophos.com"},
+		{ID: "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", Name: "Gaps", ApiHost: "https://api-eu01.central.sophos.com"},
+	}
+
+	report := c.GetPartnerHealthCheck(context.Background(), tenants)
+	a.Len(report.Rows, 3)
+	a.Equal("Gaps", report.Rows[0].Tenant.Name)
+	a.Equal(85, report.Rows[0].Score)
+	a.Equal(3, report.Rows[0].Failures)
+	a.Equal("Healthy", report.Rows[1].Tenant.Name)
+	a.Equal(100, report.Rows[1].Score)
+	a.Equal("Forbidden", report.Rows[2].Tenant.Name)
+	a.Error(report.Rows[2].Err)
+
+	var buf bytes.Buffer
+	a.NoError(report.WriteTable(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	a.Len(lines, 4)
+	a.Equal([]string{"RANK", "TENANT", "SCORE", "PROTECTION", "POLICY", "EXCLUSIONS", "TAMPER", "FAILURES"}, strings.Fields(lines[0]))
+	a.Equal([]string{"1", "Gaps", "85", "90", "70", "80", "100", "3"}, strings.Fields(lines[1]))
+	a.True(strings.HasPrefix(strings.Fields(lines[3])[0], "-"))
+}
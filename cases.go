@@ -0,0 +1,416 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Implementation for sophos central CASES API
+https://developer.sophos.com/docs/cases-v1/1/overview
+URIs are relative to https://api-{dataRegion}.central.sophos.com/cases/v1
+
+GET		/cases
+POST	/cases
+GET		/cases/{caseId}
+PATCH	/cases/{caseId}
+GET		/cases/{caseId}/impacted-entities
+GET		/cases/{caseId}/detections
+
+Case lists are paged as alerts are: GetCases returns one page and the pages details, and the
+next page is asked for with pageFromKey (or page).  EachCase walks every page.
+*/
+
+// GetCases accepts allowed query params and returns one page of cases.  Default page size is 50
+// and max page size is 100.
+// Allowed query params: status, severity, assigneeId, managedBy, verdict, search, createdAfter,
+// createdBefore, updatedAfter, updatedBefore, sort, page, pageSize, pageFromKey, pageTotal
+func (c *Client) GetCases(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (Cases, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases
+
+	if err := verifyCasesQueryParams(queryParams); err != nil {
+		return Cases{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/cases/v1/cases", queryParams, nil)
+	if err != nil {
+		return Cases{}, err
+	}
+
+	return UnmarshalCases(b)
+}
+
+// EachCase calls fn for every case matching queryParams, reading one page at a time.
+func (c *Client) EachCase(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string, fn func(CaseItem) error) error {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases
+
+	if err := verifyCasesQueryParams(queryParams); err != nil {
+		return err
+	}
+
+	return c.tenantPages(ctx, tenant, "/cases/v1/cases", queryParams, func(b []byte) (Pages, error) {
+		cs, err := UnmarshalCases(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		for _, ci := range cs.Items {
+			if err := fn(ci); err != nil {
+				return Pages{}, err
+			}
+		}
+		return cs.Pages, nil
+	})
+}
+
+// GetCase returns one case by id.
+func (c *Client) GetCase(ctx context.Context, tenant TenantsResponseItem, caseID string) (CaseItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases/{caseId}
+
+	if _, err := uuid.Parse(caseID); err != nil {
+		return CaseItem{}, fmt.Errorf("%s: %w", ErrCaseID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/cases/v1/cases/%s", caseID), nil, nil)
+	if err != nil {
+		return CaseItem{}, err
+	}
+
+	return UnmarshalCaseItem(b)
+}
+
+// CreateCase opens a case, optionally with detections attached.
+func (c *Client) CreateCase(ctx context.Context, tenant TenantsResponseItem, ccr CreateCaseRequest) (CaseItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases
+
+	if err := ccr.validate(); err != nil {
+		return CaseItem{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/cases/v1/cases", nil, ccr)
+	if err != nil {
+		return CaseItem{}, err
+	}
+
+	return UnmarshalCaseItem(b)
+}
+
+// UpdateCase changes a case.  Fields left empty in ucr are not changed.
+func (c *Client) UpdateCase(ctx context.Context, tenant TenantsResponseItem, caseID string, ucr UpdateCaseRequest) (CaseItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases/{caseId}
+
+	if _, err := uuid.Parse(caseID); err != nil {
+		return CaseItem{}, fmt.Errorf("%s: %w", ErrCaseID, err)
+	}
+	if err := ucr.validate(); err != nil {
+		return CaseItem{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/cases/v1/cases/%s", caseID), nil, ucr)
+	if err != nil {
+		return CaseItem{}, err
+	}
+
+	return UnmarshalCaseItem(b)
+}
+
+// SetCaseStatus changes the status of a case.
+func (c *Client) SetCaseStatus(ctx context.Context, tenant TenantsResponseItem, caseID string, status CaseStatus) (CaseItem, error) {
+	return c.UpdateCase(ctx, tenant, caseID, UpdateCaseRequest{Status: status})
+}
+
+// AssignCase assigns a case to the admin with the given id.
+func (c *Client) AssignCase(ctx context.Context, tenant TenantsResponseItem, caseID, assigneeID string) (CaseItem, error) {
+	return c.UpdateCase(ctx, tenant, caseID, UpdateCaseRequest{Assignee: &CaseAssigneeRef{ID: assigneeID}})
+}
+
+// SetCaseSeverity changes the severity of a case.
+func (c *Client) SetCaseSeverity(ctx context.Context, tenant TenantsResponseItem, caseID string, severity CaseSeverity) (CaseItem, error) {
+	return c.UpdateCase(ctx, tenant, caseID, UpdateCaseRequest{Severity: severity})
+}
+
+// GetCaseImpactedEntities returns the devices, users and other entities a case touches.
+func (c *Client) GetCaseImpactedEntities(ctx context.Context, tenant TenantsResponseItem, caseID string) (CaseImpactedEntities, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases/{caseId}/impacted-entities
+
+	if _, err := uuid.Parse(caseID); err != nil {
+		return CaseImpactedEntities{}, fmt.Errorf("%s: %w", ErrCaseID, err)
+	}
+
+	var all CaseImpactedEntities
+	err := c.tenantPages(ctx, tenant, fmt.Sprintf("/cases/v1/cases/%s/impacted-entities", caseID), nil, func(b []byte) (Pages, error) {
+		ie, err := UnmarshalCaseImpactedEntities(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, ie.Items...)
+		all.Pages = ie.Pages
+		return ie.Pages, nil
+	})
+	if err != nil {
+		return CaseImpactedEntities{}, err
+	}
+
+	return all, nil
+}
+
+// GetCaseDetections returns the detections attached to a case.
+func (c *Client) GetCaseDetections(ctx context.Context, tenant TenantsResponseItem, caseID string) (Detections, error) {
+	// https://api-{dataRegion}.central.sophos.com/cases/v1/cases/{caseId}/detections
+
+	if _, err := uuid.Parse(caseID); err != nil {
+		return Detections{}, fmt.Errorf("%s: %w", ErrCaseID, err)
+	}
+
+	var all Detections
+	err := c.tenantPages(ctx, tenant, fmt.Sprintf("/cases/v1/cases/%s/detections", caseID), nil, func(b []byte) (Pages, error) {
+		ds, err := UnmarshalDetections(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, ds.Items...)
+		all.Pages = ds.Pages
+		return ds.Pages, nil
+	})
+	if err != nil {
+		return Detections{}, err
+	}
+
+	return all, nil
+}
+
+func verifyCasesQueryParams(qp map[string]string) error {
+
+	var errMsgs []string
+	for k, v := range qp {
+		switch k {
+		case "status":
+			for _, s := range strings.Split(v, ",") {
+				if !CaseStatus(s).valid() {
+					errMsgs = append(errMsgs, "status is invalid")
+					break
+				}
+			}
+		case "severity":
+			for _, s := range strings.Split(v, ",") {
+				if !CaseSeverity(s).valid() {
+					errMsgs = append(errMsgs, "severity is invalid")
+					break
+				}
+			}
+		case "assigneeId":
+			if !areValidUUIDs(strings.Split(v, ",")) {
+				errMsgs = append(errMsgs, "assigneeId is invalid")
+			}
+		case "createdAfter", "createdBefore", "updatedAfter", "updatedBefore":
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				errMsgs = append(errMsgs, k+" time invalid")
+			}
+		case "sort":
+			if !isValidSort(v) {
+				errMsgs = append(errMsgs, "sort value is invalid")
+			}
+		case "pageSize":
+			if !isValidPageSize(v) {
+				errMsgs = append(errMsgs, "pageSize is invalid")
+			}
+		case "pageTotal":
+			if !isValidPageTotal(v) {
+				errMsgs = append(errMsgs, "pageTotal is invalid")
+			}
+		}
+	}
+
+	if len(errMsgs) < 1 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", ErrInvalidQueryParams, errors.New(strings.Join(errMsgs, "\n")))
+}
+
+func (ccr CreateCaseRequest) validate() error {
+	if strings.TrimSpace(ccr.Name) == "" {
+		return ErrMissingInput{Argument: "Name"}
+	}
+	if ccr.Severity != "" && !ccr.Severity.valid() {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Severity"}, Value: ccr.Severity}
+	}
+	if ccr.Assignee != nil {
+		if _, err := uuid.Parse(ccr.Assignee.ID); err != nil {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Assignee"}, Value: ccr.Assignee.ID}
+		}
+	}
+	return nil
+}
+
+func (ucr UpdateCaseRequest) validate() error {
+	if ucr.Status == "" && ucr.Severity == "" && ucr.Assignee == nil && ucr.Name == "" && ucr.Overview == "" {
+		return ErrMissingInput{Argument: "UpdateCaseRequest"}
+	}
+	if ucr.Status != "" && !ucr.Status.valid() {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Status"}, Value: ucr.Status}
+	}
+	if ucr.Severity != "" && !ucr.Severity.valid() {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Severity"}, Value: ucr.Severity}
+	}
+	if ucr.Assignee != nil {
+		if _, err := uuid.Parse(ucr.Assignee.ID); err != nil {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Assignee"}, Value: ucr.Assignee.ID}
+		}
+	}
+	return nil
+}
+
+func (s CaseStatus) valid() bool {
+	switch s {
+	case CaseNew, CaseInvestigating, CaseActionRequired, CaseResolved:
+		return true
+	}
+	return false
+}
+
+func (s CaseSeverity) valid() bool {
+	switch s {
+	case CaseCritical, CaseHigh, CaseMedium, CaseLow, CaseInformational:
+		return true
+	}
+	return false
+}
+
+func UnmarshalCases(data []byte) (Cases, error) {
+	var r Cases
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Cases{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalCaseItem(data []byte) (CaseItem, error) {
+	var r CaseItem
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return CaseItem{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalCaseImpactedEntities(data []byte) (CaseImpactedEntities, error) {
+	var r CaseImpactedEntities
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return CaseImpactedEntities{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type CaseStatus string
+
+const (
+	CaseNew            CaseStatus = "new"
+	CaseInvestigating  CaseStatus = "investigating"
+	CaseActionRequired CaseStatus = "actionRequired"
+	CaseResolved       CaseStatus = "resolved"
+)
+
+type CaseSeverity string
+
+const (
+	CaseCritical      CaseSeverity = "critical"
+	CaseHigh          CaseSeverity = "high"
+	CaseMedium        CaseSeverity = "medium"
+	CaseLow           CaseSeverity = "low"
+	CaseInformational CaseSeverity = "informational"
+)
+
+type CaseManagedBy string
+
+const (
+	CaseManagedBySelf   CaseManagedBy = "self"
+	CaseManagedBySophos CaseManagedBy = "sophos"
+)
+
+type CaseVerdict string
+
+const (
+	CaseTruePositive  CaseVerdict = "truePositive"
+	CaseFalsePositive CaseVerdict = "falsePositive"
+	CaseNoVerdict     CaseVerdict = "noVerdict"
+)
+
+type Cases struct {
+	Items []CaseItem `json:"items"`
+	Pages Pages      `json:"pages"`
+}
+
+type CaseItem struct {
+	ID               string         `json:"id"`
+	Type             string         `json:"type,omitempty"`
+	Name             string         `json:"name"`
+	Overview         string         `json:"overview,omitempty"`
+	Status           CaseStatus     `json:"status"`
+	Severity         CaseSeverity   `json:"severity"`
+	ManagedBy        CaseManagedBy  `json:"managedBy,omitempty"`
+	Verdict          CaseVerdict    `json:"verdict,omitempty"`
+	Escalated        bool           `json:"escalated"`
+	Assignee         *CaseAssignee  `json:"assignee,omitempty"`
+	CreatedBy        *ItemCreatedBy `json:"createdBy,omitempty"`
+	DetectionCount   int            `json:"detectionCount"`
+	InitialDetection *CaseDetection `json:"initialDetection,omitempty"`
+	Tenant           *Tenant        `json:"tenant,omitempty"`
+	CreatedAt        time.Time      `json:"createdAt"`
+	UpdatedAt        *time.Time     `json:"updatedAt,omitempty"`
+	ResolvedAt       *time.Time     `json:"resolvedAt,omitempty"`
+}
+
+type CaseAssignee struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+type CaseDetection struct {
+	ID string `json:"id"`
+}
+
+// CaseAssigneeRef names the admin a case is assigned to.
+type CaseAssigneeRef struct {
+	ID string `json:"id"`
+}
+
+type CreateCaseRequest struct {
+	Name         string           `json:"name"`
+	Overview     string           `json:"overview,omitempty"`
+	Severity     CaseSeverity     `json:"severity,omitempty"`
+	Assignee     *CaseAssigneeRef `json:"assignee,omitempty"`
+	DetectionIDs []string         `json:"detectionIds,omitempty"`
+}
+
+type UpdateCaseRequest struct {
+	Name     string           `json:"name,omitempty"`
+	Overview string           `json:"overview,omitempty"`
+	Status   CaseStatus       `json:"status,omitempty"`
+	Severity CaseSeverity     `json:"severity,omitempty"`
+	Assignee *CaseAssigneeRef `json:"assignee,omitempty"`
+}
+
+type CaseImpactedEntities struct {
+	Items []CaseImpactedEntity `json:"items"`
+	Pages Pages                `json:"pages"`
+}
+
+// CaseImpactedEntity is a device, user or other entity a case touches.  For devices the ID is
+// the endpoint ID.
+type CaseImpactedEntity struct {
+	ID             string              `json:"id"`
+	Type           DetectionEntityType `json:"type"`
+	Name           string              `json:"name,omitempty"`
+	DetectionCount int                 `json:"detectionCount,omitempty"`
+	FirstSeenAt    *time.Time          `json:"firstSeenAt,omitempty"`
+	LastSeenAt     *time.Time          `json:"lastSeenAt,omitempty"`
+}
@@ -0,0 +1,78 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_EachCase(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	pages := map[string]string{
+		"":   `{"items": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "Suspicious PowerShell", "status": "new", "severity": "high", "detectionCount": 3, "createdAt": "2021-05-05T11:47:30.148Z"}], "pages": {"fromKey": "", "nextKey": "k2", "size": 1}}`,
+		"k2": `{"items": [{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "name": "Lateral movement", "status": "investigating", "severity": "critical", "assignee": {"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504", "name": "Jane"}, "createdAt": "2021-05-06T08:00:00Z"}], "pages": {"fromKey": "k2", "size": 1}}`,
+	}
+
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/cases/v1/cases", req.URL.Path)
+			a.Equal("new,investigating", req.URL.Query().Get("status"))
+			body := pages[req.URL.Query().Get("pageFromKey")]
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	var names []string
+	err := c.EachCase(context.Background(), tenant, map[string]string{"status": "new,investigating"}, func(ci CaseItem) error {
+		names = append(names, ci.Name)
+		return nil
+	})
+	a.NoError(err)
+	a.Equal([]string{"Suspicious PowerShell", "Lateral movement"}, names)
+
+	cs, err := c.GetCases(context.Background(), tenant, map[string]string{"status": "new,investigating"})
+	a.NoError(err)
+	a.Len(cs.Items, 1)
+	a.Equal("k2", cs.Pages.NextKey)
+	a.Equal(CaseHigh, cs.Items[0].Severity)
+
+	_, err = c.GetCases(context.Background(), tenant, map[string]string{"status": "closed"})
+	a.Error(err)
+}
+
+func TestClient_UpdateCase(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var gotMethod, gotBody string
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: httpClientWithRequestRecorder(200,
+		`{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "Suspicious PowerShell", "status": "resolved", "severity": "high", "createdAt": "2021-05-05T11:47:30.148Z"}`,
+		func(req *http.Request, body []byte) {
+			gotMethod = req.Method
+			gotBody = string(body)
+		})}
+
+	ci, err := c.SetCaseStatus(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", CaseResolved)
+	a.NoError(err)
+	a.Equal("PATCH", gotMethod)
+	a.JSONEq(`{"status": "resolved"}`, gotBody)
+	a.Equal(CaseResolved, ci.Status)
+
+	_, err = c.AssignCase(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", "03b43abe-4f41-4734-b6d6-70b2fbdc2504")
+	a.NoError(err)
+	a.JSONEq(`{"assignee": {"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504"}}`, gotBody)
+
+	_, err = c.SetCaseSeverity(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", "urgent")
+	a.Error(err)
+	_, err = c.UpdateCase(context.Background(), tenant, "not a case", UpdateCaseRequest{Status: CaseResolved})
+	a.Error(err)
+}
@@ -25,6 +25,7 @@ type Client struct {
 	Partner      *PartnerService
 	Organization *OrganizationService
 	Tenant       *TenantService
+	limiter      *rateLimiter
 }
 
 // NewClient returns a SophosCentral client that can be used to access all functionality
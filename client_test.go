@@ -400,6 +400,23 @@ func httpClientWithRoundTripper(statusCode int, response string) *http.Client {
 	}
 }
 
+// httpClientWithRequestRecorder returns the canned response like httpClientWithRoundTripper
+// and hands each request and its body to record so tests can check what was sent.
+func httpClientWithRequestRecorder(statusCode int, response string, record func(req *http.Request, body []byte)) *http.Client {
+	return &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			var body []byte
+			if req.Body != nil {
+				body, _ = ioutil.ReadAll(req.Body)
+			}
+			record(req, body)
+			return &http.Response{
+				StatusCode: statusCode,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(response)),
+			}
+		}),
+	}
+}
 
 type roundTripWithErrorFunc func(req *http.Request) error
 
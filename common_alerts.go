@@ -155,24 +155,9 @@ func (c *Client) RespondToAlert(ctx context.Context, tenant TenantsResponseItem,
 		Message: actionMessage,
 	}
 
-	payload, err := rta.Marshal()
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/common/v1/alerts/%s/actions", alertID), nil, rta)
 	if err != nil {
-		return  AlertActionResponse{},  fmt.Errorf("%s: %s", ErrMarshalFailed, err)
-	}
-
-	reqURL := fmt.Sprintf("%s/common/v1/alerts/%s/actions", tenant.ApiHost,alertID)
-
-	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewBuffer(payload))
-	if err != nil{
-		return    AlertActionResponse{}, fmt.Errorf("%s: %w", ErrFailedToCreateRequest, err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID",tenant.ID)
-	c.token.SetAuthHeader(req)
-
-	b, err := MakeRequest(c.httpClient, req)
-	if err != nil {
-		return   AlertActionResponse{}, fmt.Errorf("%s: %w", ErrHttpDo, err)
+		return   AlertActionResponse{}, err
 	}
 
 	aar, err := UnmarshalAlertActionResponse(b)
@@ -0,0 +1,304 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Implementation for sophos central DETECTIONS API
+https://developer.sophos.com/docs/detections-v1/1/overview
+
+POST	/queries/detections
+GET		/queries/detections/{runId}
+GET		/queries/detections/{runId}/results
+
+Detections are read with a run, as data lake queries are: the run is created with the
+filters, polled until finished, then its results are paged.
+*/
+
+// MaxDetectionSeverity is the highest severity Central gives a detection.
+const MaxDetectionSeverity = 10
+
+var mitreTacticID = regexp.MustCompile(`^TA\d{4}$`)
+
+// CreateDetectionRun starts a search for the detections drr matches.
+func (c *Client) CreateDetectionRun(ctx context.Context, tenant TenantsResponseItem, drr DetectionRunRequest) (DetectionRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/detections/v1/queries/detections
+
+	if err := drr.validate(); err != nil {
+		return DetectionRun{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/detections/v1/queries/detections", nil, drr)
+	if err != nil {
+		return DetectionRun{}, err
+	}
+
+	return UnmarshalDetectionRun(b)
+}
+
+// GetDetectionRun returns the status of a detection run.
+func (c *Client) GetDetectionRun(ctx context.Context, tenant TenantsResponseItem, runID string) (DetectionRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/detections/v1/queries/detections/{runId}
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return DetectionRun{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/detections/v1/queries/detections/%s", runID), nil, nil)
+	if err != nil {
+		return DetectionRun{}, err
+	}
+
+	return UnmarshalDetectionRun(b)
+}
+
+// WaitForDetectionRun polls a detection run every pollInterval until it has finished.  The
+// last status seen is returned along with any error, including when ctx is done.
+func (c *Client) WaitForDetectionRun(ctx context.Context, tenant TenantsResponseItem, runID string, pollInterval time.Duration) (DetectionRun, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultQueryPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		run, err := c.GetDetectionRun(ctx, tenant, runID)
+		if err != nil {
+			return run, err
+		}
+		if run.Status == QueryRunFinished {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EachDetection pages through the results of a finished detection run, calling fn for each
+// detection.  Only one page is held at a time.
+// Allowed query params: pageSize
+func (c *Client) EachDetection(ctx context.Context, tenant TenantsResponseItem, runID string, queryParams map[string]string, fn func(Detection) error) error {
+	// https://api-{dataRegion}.central.sophos.com/detections/v1/queries/detections/{runId}/results
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	return c.tenantPages(ctx, tenant, fmt.Sprintf("/detections/v1/queries/detections/%s/results", runID), queryParams, func(b []byte) (Pages, error) {
+		ds, err := UnmarshalDetections(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		for _, d := range ds.Items {
+			if err := fn(d); err != nil {
+				return Pages{}, err
+			}
+		}
+		return ds.Pages, nil
+	})
+}
+
+// GetDetections returns every result of a finished detection run.
+func (c *Client) GetDetections(ctx context.Context, tenant TenantsResponseItem, runID string) (Detections, error) {
+
+	var all Detections
+	err := c.EachDetection(ctx, tenant, runID, nil, func(d Detection) error {
+		all.Items = append(all.Items, d)
+		return nil
+	})
+	if err != nil {
+		return Detections{}, err
+	}
+
+	return all, nil
+}
+
+// Endpoint returns the endpoint in eps the detection was raised on.
+func (d Detection) Endpoint(eps Endpoints) (EndpointItem, bool) {
+	if d.Device == nil || d.Device.ID == "" {
+		return EndpointItem{}, false
+	}
+	return eps.Find(d.Device.ID)
+}
+
+// LinkDetections pairs each detection with the endpoint in eps it was raised on.  Endpoint is
+// nil for detections not raised on one of eps.
+func LinkDetections(ds []Detection, eps Endpoints) []LinkedDetection {
+	linked := make([]LinkedDetection, 0, len(ds))
+	for _, d := range ds {
+		ld := LinkedDetection{Detection: d}
+		if ep, ok := d.Endpoint(eps); ok {
+			ld.Endpoint = &ep
+		}
+		linked = append(linked, ld)
+	}
+	return linked
+}
+
+// Tactics returns the ids of the MITRE ATT&CK tactics the detection maps to.
+func (d Detection) Tactics() []string {
+	var ids []string
+	for _, ma := range d.MitreAttacks {
+		ids = append(ids, ma.Tactic.ID)
+	}
+	return ids
+}
+
+func (drr DetectionRunRequest) validate() error {
+	if drr.From.IsZero() {
+		return ErrMissingInput{Argument: "From"}
+	}
+	if drr.To.IsZero() {
+		return ErrMissingInput{Argument: "To"}
+	}
+	if !drr.From.Before(drr.To) {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "From"}, Value: drr.From}
+	}
+	if drr.Severity != nil {
+		if drr.Severity.Min < 0 || drr.Severity.Max > MaxDetectionSeverity || (drr.Severity.Max > 0 && drr.Severity.Min > drr.Severity.Max) {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Severity"}, Value: *drr.Severity}
+		}
+	}
+	for _, t := range drr.MitreTactics {
+		if !mitreTacticID.MatchString(t) {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "MitreTactics"}, Value: t}
+		}
+	}
+	if len(drr.DeviceIDs) > 0 && !areValidUUIDs(drr.DeviceIDs) {
+		return ErrEndpointID
+	}
+	return nil
+}
+
+func UnmarshalDetectionRun(data []byte) (DetectionRun, error) {
+	var r DetectionRun
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return DetectionRun{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalDetections(data []byte) (Detections, error) {
+	var r Detections
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Detections{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// DetectionRunRequest selects detections raised between From and To.  The other filters are
+// optional and combine with and.
+type DetectionRunRequest struct {
+	From         time.Time             `json:"from"`
+	To           time.Time             `json:"to"`
+	Severity     *DetectionSeverity    `json:"severity,omitempty"`
+	MitreTactics []string              `json:"mitreAttackTactics,omitempty"`
+	EntityTypes  []DetectionEntityType `json:"entityTypes,omitempty"`
+	DeviceIDs    []string              `json:"deviceIds,omitempty"`
+}
+
+// DetectionSeverity is an inclusive range of severities, 0 to MaxDetectionSeverity.  A Max of 0
+// leaves the range open at the top.
+type DetectionSeverity struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+type DetectionEntityType string
+
+const (
+	DetectionEntityDevice  DetectionEntityType = "device"
+	DetectionEntityUser    DetectionEntityType = "user"
+	DetectionEntityNetwork DetectionEntityType = "network"
+	DetectionEntityEmail   DetectionEntityType = "email"
+	DetectionEntityCloud   DetectionEntityType = "cloud"
+)
+
+type DetectionRun struct {
+	ID          string         `json:"id"`
+	Status      QueryRunStatus `json:"status"`
+	Result      QueryRunResult `json:"result"`
+	From        time.Time      `json:"from"`
+	To          time.Time      `json:"to"`
+	ResultCount int            `json:"resultCount,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	FinishedAt  *time.Time     `json:"finishedAt,omitempty"`
+	ExpiresAt   *time.Time     `json:"expiresAt,omitempty"`
+}
+
+type Detections struct {
+	Items []Detection `json:"items"`
+	Pages Pages       `json:"pages"`
+}
+
+type Detection struct {
+	ID                string              `json:"id"`
+	Type              string              `json:"type"`
+	Severity          int                 `json:"severity"`
+	Category          string              `json:"category,omitempty"`
+	AttackType        string              `json:"attackType,omitempty"`
+	DetectionRule     string              `json:"detectionRule"`
+	Description       string              `json:"detectionDescription,omitempty"`
+	EntityType        DetectionEntityType `json:"entityType,omitempty"`
+	MitreAttacks      []MitreAttack       `json:"mitreAttacks,omitempty"`
+	Device            *DetectionDevice    `json:"device,omitempty"`
+	Sensor            *DetectionSensor    `json:"sensor,omitempty"`
+	Time              time.Time           `json:"time"`
+	SensorGeneratedAt *time.Time          `json:"sensorGeneratedAt,omitempty"`
+	RawData           json.RawMessage     `json:"rawData,omitempty"`
+}
+
+type MitreAttack struct {
+	Tactic MitreTactic `json:"tactic"`
+}
+
+type MitreTactic struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Techniques []MitreTechnique `json:"techniques,omitempty"`
+}
+
+type MitreTechnique struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DetectionDevice is the device a detection was raised on.  For endpoints and servers the ID is
+// the endpoint ID.
+type DetectionDevice struct {
+	ID         string `json:"id"`
+	EntityType string `json:"entityType,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Hostname   string `json:"hostname,omitempty"`
+}
+
+type DetectionSensor struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type LinkedDetection struct {
+	Detection Detection
+	Endpoint  *EndpointItem
+}
@@ -0,0 +1,53 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_GetDetections(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var gotPath string
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: httpClientWithRequestRecorder(200, `{
+		"items": [
+			{"id": "det-1", "type": "Threat", "severity": 8, "detectionRule": "WIN-EXE-PRC-POWERSHELL-1", "time": "2021-05-05T11:47:30.148Z",
+			 "mitreAttacks": [{"tactic": {"id": "TA0002", "name": "Execution", "techniques": [{"id": "T1059.001", "name": "PowerShell"}]}}],
+			 "device": {"id": "bc893b97-86a8-41aa-b65c-910e11505605", "entityType": "device", "type": "computer"}},
+			{"id": "det-2", "type": "Threat", "severity": 3, "detectionRule": "AWS-1", "time": "2021-05-05T12:00:00Z"}
+		],
+		"pages": {"current": 1, "total": 1}}`,
+		func(req *http.Request, body []byte) {
+			gotPath = req.URL.Path
+		})}
+
+	ds, err := c.GetDetections(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14")
+	a.NoError(err)
+	a.Equal("/detections/v1/queries/detections/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/results", gotPath)
+	a.Len(ds.Items, 2)
+	a.Equal([]string{"TA0002"}, ds.Items[0].Tactics())
+	a.Equal("T1059.001", ds.Items[0].MitreAttacks[0].Tactic.Techniques[0].ID)
+
+	eps := Endpoints{Item: []EndpointItem{{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Hostname: "WIN10-01"}}}
+	linked := LinkDetections(ds.Items, eps)
+	a.Equal("WIN10-01", linked[0].Endpoint.Hostname)
+	a.Nil(linked[1].Endpoint)
+}
+
+func TestDetectionRunRequest_validate(t *testing.T) {
+	a := assert.New(t)
+
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	a.NoError(DetectionRunRequest{From: from, To: to, Severity: &DetectionSeverity{Min: 5}, MitreTactics: []string{"TA0002"}}.validate())
+	a.Error(DetectionRunRequest{From: from, To: to, Severity: &DetectionSeverity{Min: 5, Max: 11}}.validate())
+	a.Error(DetectionRunRequest{From: from, To: to, MitreTactics: []string{"Execution"}}.validate())
+	a.Error(DetectionRunRequest{From: to, To: from}.validate())
+}
@@ -0,0 +1,166 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Mailboxes for the sophos central EMAIL API
+https://developer.sophos.com/docs/email-v1/1/overview
+
+GET		/mailboxes
+GET		/mailboxes/{mailboxId}
+GET		/mailboxes/{mailboxId}/settings
+PATCH	/mailboxes/{mailboxId}/settings
+
+Allow and block entries for one mailbox are in the sender lists, with the mailbox id set.
+*/
+
+// GetMailboxes returns the protected mailboxes of a tenant.
+// Allowed query params: search, searchFields, type, page, pageSize, pageTotal
+func (c *Client) GetMailboxes(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (Mailboxes, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/mailboxes
+
+	var all Mailboxes
+	err := c.tenantPages(ctx, tenant, "/email/v1/mailboxes", queryParams, func(b []byte) (Pages, error) {
+		mbs, err := UnmarshalMailboxes(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, mbs.Items...)
+		all.Pages = mbs.Pages
+		return mbs.Pages, nil
+	})
+	if err != nil {
+		return Mailboxes{}, err
+	}
+
+	return all, nil
+}
+
+// GetMailbox returns one mailbox by id.
+func (c *Client) GetMailbox(ctx context.Context, tenant TenantsResponseItem, mailboxID string) (MailboxItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/mailboxes/{mailboxId}
+
+	if _, err := uuid.Parse(mailboxID); err != nil {
+		return MailboxItem{}, fmt.Errorf("%s: %w", ErrMailboxID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/email/v1/mailboxes/%s", mailboxID), nil, nil)
+	if err != nil {
+		return MailboxItem{}, err
+	}
+
+	return UnmarshalMailboxItem(b)
+}
+
+// GetMailboxSettings returns the settings of one mailbox.
+func (c *Client) GetMailboxSettings(ctx context.Context, tenant TenantsResponseItem, mailboxID string) (MailboxSettings, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/mailboxes/{mailboxId}/settings
+
+	if _, err := uuid.Parse(mailboxID); err != nil {
+		return MailboxSettings{}, fmt.Errorf("%s: %w", ErrMailboxID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/email/v1/mailboxes/%s/settings", mailboxID), nil, nil)
+	if err != nil {
+		return MailboxSettings{}, err
+	}
+
+	return UnmarshalMailboxSettings(b)
+}
+
+// UpdateMailboxSettings changes the settings of one mailbox.  Settings left nil in ms are not
+// changed.
+func (c *Client) UpdateMailboxSettings(ctx context.Context, tenant TenantsResponseItem, mailboxID string, ms MailboxSettings) (MailboxSettings, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/mailboxes/{mailboxId}/settings
+
+	if _, err := uuid.Parse(mailboxID); err != nil {
+		return MailboxSettings{}, fmt.Errorf("%s: %w", ErrMailboxID, err)
+	}
+	if ms.QuarantineDigest != nil && !ms.QuarantineDigest.valid() {
+		return MailboxSettings{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "QuarantineDigest"}, Value: *ms.QuarantineDigest}
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/email/v1/mailboxes/%s/settings", mailboxID), nil, ms)
+	if err != nil {
+		return MailboxSettings{}, err
+	}
+
+	return UnmarshalMailboxSettings(b)
+}
+
+func (f QuarantineDigestFrequency) valid() bool {
+	switch f {
+	case DigestNever, DigestDaily, DigestTwiceDaily, DigestWeekly:
+		return true
+	}
+	return false
+}
+
+func UnmarshalMailboxes(data []byte) (Mailboxes, error) {
+	var r Mailboxes
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Mailboxes{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalMailboxItem(data []byte) (MailboxItem, error) {
+	var r MailboxItem
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return MailboxItem{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalMailboxSettings(data []byte) (MailboxSettings, error) {
+	var r MailboxSettings
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return MailboxSettings{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type Mailboxes struct {
+	Items []MailboxItem `json:"items"`
+	Pages Pages         `json:"pages"`
+}
+
+type MailboxItem struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name,omitempty"`
+	EmailAddress string         `json:"emailAddress"`
+	Aliases      []string       `json:"aliases,omitempty"`
+	Type         string         `json:"type,omitempty"`
+	User         *ItemCreatedBy `json:"user,omitempty"`
+	CreatedAt    *time.Time     `json:"createdAt,omitempty"`
+}
+
+// MailboxSettings are the settings of one mailbox.  The fields are pointers so an update
+// only sends the settings being changed.
+type MailboxSettings struct {
+	QuarantineDigest       *QuarantineDigestFrequency `json:"quarantineDigest,omitempty"`
+	EndUserRelease         *bool                      `json:"endUserRelease,omitempty"`
+	UseTenantAllowList     *bool                      `json:"useTenantAllowList,omitempty"`
+	UseTenantBlockList     *bool                      `json:"useTenantBlockList,omitempty"`
+	ImpersonationProtected *bool                      `json:"impersonationProtected,omitempty"`
+}
+
+type QuarantineDigestFrequency string
+
+const (
+	DigestNever      QuarantineDigestFrequency = "never"
+	DigestDaily      QuarantineDigestFrequency = "daily"
+	DigestTwiceDaily QuarantineDigestFrequency = "twiceDaily"
+	DigestWeekly     QuarantineDigestFrequency = "weekly"
+)
@@ -0,0 +1,40 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_UpdateMailboxSettings(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	calls := 0
+	hc := httpClientWithRequestRecorder(200, `{"quarantineDigest": "weekly", "endUserRelease": false, "useTenantAllowList": true}`, func(req *http.Request, body []byte) {
+		calls++
+		a.Equal("PATCH", req.Method)
+		a.Equal("/email/v1/mailboxes/bc893b97-86a8-41aa-b65c-910e11505605/settings", req.URL.Path)
+		a.JSONEq(`{"quarantineDigest": "weekly", "endUserRelease": false}`, string(body))
+	})
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	digest := DigestWeekly
+	release := false
+	ms, err := c.UpdateMailboxSettings(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", MailboxSettings{QuarantineDigest: &digest, EndUserRelease: &release})
+	a.NoError(err)
+	a.Equal(DigestWeekly, *ms.QuarantineDigest)
+	a.True(*ms.UseTenantAllowList)
+	a.Nil(ms.ImpersonationProtected)
+
+	monthly := QuarantineDigestFrequency("monthly")
+	_, err = c.UpdateMailboxSettings(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", MailboxSettings{QuarantineDigest: &monthly})
+	a.Error(err)
+	_, err = c.UpdateMailboxSettings(context.Background(), tenant, "not a mailbox", MailboxSettings{EndUserRelease: &release})
+	a.Error(err)
+	a.Equal(1, calls)
+}
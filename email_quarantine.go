@@ -0,0 +1,314 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*
+
+Quarantine for the sophos central EMAIL API
+https://developer.sophos.com/docs/email-v1/1/overview
+URIs are relative to https://api-{dataRegion}.central.sophos.com/email/v1
+
+POST	/quarantine/messages/search
+GET		/quarantine/messages/{messageId}/preview
+POST	/quarantine/messages/actions/release
+POST	/quarantine/messages/actions/delete
+
+Release and delete act on many messages at once and report a result for each.  The ids are
+sent MaxQuarantineActionIDs at a time.
+*/
+
+// MaxQuarantineActionIDs is the most message ids one release or delete request takes.
+const MaxQuarantineActionIDs = 100
+
+// SearchQuarantinedMessages returns one page of the quarantined messages matching qsr.  The next
+// page is asked for by setting qsr.PageFromKey to the nextKey of the page.
+func (c *Client) SearchQuarantinedMessages(ctx context.Context, tenant TenantsResponseItem, qsr QuarantineSearchRequest) (QuarantinedMessages, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/quarantine/messages/search
+
+	if err := qsr.validate(); err != nil {
+		return QuarantinedMessages{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/email/v1/quarantine/messages/search", nil, qsr)
+	if err != nil {
+		return QuarantinedMessages{}, err
+	}
+
+	return UnmarshalQuarantinedMessages(b)
+}
+
+// EachQuarantinedMessage calls fn for every quarantined message matching qsr, reading one page
+// at a time.
+func (c *Client) EachQuarantinedMessage(ctx context.Context, tenant TenantsResponseItem, qsr QuarantineSearchRequest, fn func(QuarantinedMessage) error) error {
+
+	for {
+		qm, err := c.SearchQuarantinedMessages(ctx, tenant, qsr)
+		if err != nil {
+			return err
+		}
+		for _, m := range qm.Items {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		if qm.Pages.NextKey == "" {
+			return nil
+		}
+		qsr.PageFromKey = qm.Pages.NextKey
+	}
+}
+
+// PreviewQuarantinedMessage returns the headers, attachments and other metadata of a
+// quarantined message without releasing it.
+func (c *Client) PreviewQuarantinedMessage(ctx context.Context, tenant TenantsResponseItem, messageID string) (QuarantinePreview, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/quarantine/messages/{messageId}/preview
+
+	if strings.TrimSpace(messageID) == "" {
+		return QuarantinePreview{}, ErrMessageID
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/email/v1/quarantine/messages/%s/preview", url.PathEscape(messageID)), nil, nil)
+	if err != nil {
+		return QuarantinePreview{}, err
+	}
+
+	return UnmarshalQuarantinePreview(b)
+}
+
+// ReleaseQuarantinedMessages delivers quarantined messages to their recipients.  There is a
+// result for every id; ids in a request that failed as a whole carry that request's error.
+func (c *Client) ReleaseQuarantinedMessages(ctx context.Context, tenant TenantsResponseItem, messageIDs []string) ([]QuarantineActionResult, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/quarantine/messages/actions/release
+	return c.quarantineAction(ctx, tenant, "release", messageIDs)
+}
+
+// DeleteQuarantinedMessages deletes quarantined messages.  There is a result for every id; ids
+// in a request that failed as a whole carry that request's error.
+func (c *Client) DeleteQuarantinedMessages(ctx context.Context, tenant TenantsResponseItem, messageIDs []string) ([]QuarantineActionResult, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/quarantine/messages/actions/delete
+	return c.quarantineAction(ctx, tenant, "delete", messageIDs)
+}
+
+// quarantineAction posts messageIDs to an action in batches.  The error is only for input
+// that was not sent at all; failures of sent batches are in the results.
+func (c *Client) quarantineAction(ctx context.Context, tenant TenantsResponseItem, action string, messageIDs []string) ([]QuarantineActionResult, error) {
+
+	if len(messageIDs) == 0 {
+		return nil, ErrMissingInput{Argument: "messageIDs"}
+	}
+	for _, id := range messageIDs {
+		if strings.TrimSpace(id) == "" {
+			return nil, ErrMessageID
+		}
+	}
+
+	results := make([]QuarantineActionResult, 0, len(messageIDs))
+	for start := 0; start < len(messageIDs); start += MaxQuarantineActionIDs {
+		end := start + MaxQuarantineActionIDs
+		if end > len(messageIDs) {
+			end = len(messageIDs)
+		}
+		batch := messageIDs[start:end]
+
+		b, err := c.tenantRequest(ctx, tenant, "POST", "/email/v1/quarantine/messages/actions/"+action, nil, QuarantineActionRequest{IDs: batch})
+		if err == nil {
+			var qr QuarantineActionResults
+			qr, err = UnmarshalQuarantineActionResults(b)
+			if err == nil {
+				results = append(results, qr.results(batch)...)
+				continue
+			}
+		}
+		for _, id := range batch {
+			results = append(results, QuarantineActionResult{MessageID: id, Status: QuarantineActionFailed, Err: err})
+		}
+	}
+
+	return results, nil
+}
+
+// results lines the api's results up with the ids sent.  An id the api left out is failed.
+func (r QuarantineActionResults) results(ids []string) []QuarantineActionResult {
+	byID := map[string]QuarantineActionResult{}
+	for _, res := range r.Items {
+		byID[res.MessageID] = res
+	}
+
+	out := make([]QuarantineActionResult, 0, len(ids))
+	for _, id := range ids {
+		res, ok := byID[id]
+		if !ok {
+			res = QuarantineActionResult{MessageID: id, Status: QuarantineActionFailed, Reason: "no result returned"}
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// FailedQuarantineActions returns the results that did not succeed.
+func FailedQuarantineActions(results []QuarantineActionResult) []QuarantineActionResult {
+	var failed []QuarantineActionResult
+	for _, r := range results {
+		if r.Status != QuarantineActionSucceeded {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+func (qsr QuarantineSearchRequest) validate() error {
+	if qsr.BeginDate.IsZero() {
+		return ErrMissingInput{Argument: "BeginDate"}
+	}
+	if qsr.EndDate.IsZero() {
+		return ErrMissingInput{Argument: "EndDate"}
+	}
+	if !qsr.BeginDate.Before(qsr.EndDate) {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "BeginDate"}, Value: qsr.BeginDate}
+	}
+	for _, r := range qsr.Reasons {
+		if !r.valid() {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Reasons"}, Value: r}
+		}
+	}
+	if qsr.PageSize < 0 || qsr.PageSize > 100 {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "PageSize"}, Value: qsr.PageSize}
+	}
+	return nil
+}
+
+func (r QuarantineReason) valid() bool {
+	switch r {
+	case QuarantineSpam, QuarantineMalware, QuarantineDLP, QuarantinePolicy, QuarantineSuspicious:
+		return true
+	}
+	return false
+}
+
+func UnmarshalQuarantinedMessages(data []byte) (QuarantinedMessages, error) {
+	var r QuarantinedMessages
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return QuarantinedMessages{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalQuarantinePreview(data []byte) (QuarantinePreview, error) {
+	var r QuarantinePreview
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return QuarantinePreview{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalQuarantineActionResults(data []byte) (QuarantineActionResults, error) {
+	var r QuarantineActionResults
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return QuarantineActionResults{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// QuarantineSearchRequest selects quarantined messages received between BeginDate and
+// EndDate.  The other filters are optional and combine with and; Sender, Recipient and Subject
+// match on part of the value.
+type QuarantineSearchRequest struct {
+	BeginDate   time.Time          `json:"beginDate"`
+	EndDate     time.Time          `json:"endDate"`
+	Sender      string             `json:"sender,omitempty"`
+	Recipient   string             `json:"recipient,omitempty"`
+	Subject     string             `json:"subject,omitempty"`
+	Reasons     []QuarantineReason `json:"reasons,omitempty"`
+	PageFromKey string             `json:"pageFromKey,omitempty"`
+	PageSize    int                `json:"pageSize,omitempty"`
+}
+
+type QuarantineReason string
+
+const (
+	QuarantineSpam       QuarantineReason = "spam"
+	QuarantineMalware    QuarantineReason = "malware"
+	QuarantineDLP        QuarantineReason = "dlp"
+	QuarantinePolicy     QuarantineReason = "policy"
+	QuarantineSuspicious QuarantineReason = "suspicious"
+)
+
+type QuarantinedMessages struct {
+	Items []QuarantinedMessage `json:"items"`
+	Pages Pages                `json:"pages"`
+}
+
+type QuarantinedMessage struct {
+	ID            string           `json:"id"`
+	Sender        string           `json:"sender"`
+	Recipients    []string         `json:"recipients"`
+	Subject       string           `json:"subject"`
+	Reason        QuarantineReason `json:"reason"`
+	Size          int64            `json:"size,omitempty"`
+	HasAttachment bool             `json:"hasAttachment"`
+	Direction     string           `json:"direction,omitempty"`
+	MailboxID     string           `json:"mailboxId,omitempty"`
+	ReceivedAt    time.Time        `json:"receivedAt"`
+	ExpiresAt     *time.Time       `json:"expiresAt,omitempty"`
+}
+
+// QuarantinePreview is the metadata of a quarantined message.  The body is not included.
+type QuarantinePreview struct {
+	ID          string                 `json:"id"`
+	Sender      string                 `json:"sender"`
+	Recipients  []string               `json:"recipients"`
+	Cc          []string               `json:"cc,omitempty"`
+	Subject     string                 `json:"subject"`
+	Reason      QuarantineReason       `json:"reason"`
+	Detection   string                 `json:"detection,omitempty"`
+	Size        int64                  `json:"size,omitempty"`
+	Headers     []EmailHeader          `json:"headers,omitempty"`
+	Attachments []QuarantineAttachment `json:"attachments,omitempty"`
+	ReceivedAt  time.Time              `json:"receivedAt"`
+}
+
+type EmailHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type QuarantineAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+type QuarantineActionRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type QuarantineActionResults struct {
+	Items []QuarantineActionResult `json:"items"`
+}
+
+// QuarantineActionResult is the outcome of releasing or deleting one message.  Err is set when
+// the request carrying the message failed.
+type QuarantineActionResult struct {
+	MessageID string                 `json:"id"`
+	Status    QuarantineActionStatus `json:"status"`
+	Reason    string                 `json:"reason,omitempty"`
+	Err       error                  `json:"-"`
+}
+
+type QuarantineActionStatus string
+
+const (
+	QuarantineActionSucceeded QuarantineActionStatus = "succeeded"
+	QuarantineActionFailed    QuarantineActionStatus = "failed"
+)
@@ -0,0 +1,79 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_ReleaseQuarantinedMessages(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	batches := 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			batches++
+			a.Equal("/email/v1/quarantine/messages/actions/release", req.URL.Path)
+			var qar QuarantineActionRequest
+			b, _ := ioutil.ReadAll(req.Body)
+			a.NoError(json.Unmarshal(b, &qar))
+			if batches == 2 {
+				return &http.Response{StatusCode: 500, Body: ioutil.NopCloser(bytes.NewBufferString(`{"error": "serverError"}`))}
+			}
+
+			// the first message of each batch is already gone and the last has no result
+			var items []QuarantineActionResult
+			for i, id := range qar.IDs[:len(qar.IDs)-1] {
+				status := QuarantineActionSucceeded
+				if i == 0 {
+					status = QuarantineActionFailed
+				}
+				items = append(items, QuarantineActionResult{MessageID: id, Status: status})
+			}
+			body, _ := json.Marshal(QuarantineActionResults{Items: items})
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBuffer(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	var ids []string
+	for i := 0; i < MaxQuarantineActionIDs+20; i++ {
+		ids = append(ids, fmt.Sprintf("msg-%03d", i))
+	}
+
+	results, err := c.ReleaseQuarantinedMessages(context.Background(), tenant, ids)
+	a.NoError(err)
+	a.Equal(2, batches)
+	a.Len(results, len(ids))
+	a.Equal("msg-000", results[0].MessageID)
+	a.Equal(QuarantineActionFailed, results[0].Status)
+	a.Equal(QuarantineActionSucceeded, results[1].Status)
+	a.Equal("no result returned", results[MaxQuarantineActionIDs-1].Reason)
+	a.Error(results[MaxQuarantineActionIDs].Err)
+
+	a.Len(FailedQuarantineActions(results), 2+20)
+
+	_, err = c.DeleteQuarantinedMessages(context.Background(), tenant, []string{"msg-1", ""})
+	a.Error(err)
+}
+
+func TestQuarantineSearchRequest_validate(t *testing.T) {
+	a := assert.New(t)
+
+	end := time.Now()
+	begin := end.Add(-7 * 24 * time.Hour)
+	a.NoError(QuarantineSearchRequest{BeginDate: begin, EndDate: end, Sender: "@example.com", Reasons: []QuarantineReason{QuarantineSpam}}.validate())
+	a.Error(QuarantineSearchRequest{BeginDate: begin, EndDate: end, Reasons: []QuarantineReason{"virus"}}.validate())
+	a.Error(QuarantineSearchRequest{BeginDate: end, EndDate: begin}.validate())
+	a.Error(QuarantineSearchRequest{EndDate: end}.validate())
+}
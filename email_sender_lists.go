@@ -0,0 +1,469 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Sender allow and block lists for the sophos central EMAIL API
+https://developer.sophos.com/docs/email-v1/1/overview
+
+GET		/settings/allow-list
+POST	/settings/allow-list
+GET		/settings/allow-list/{entryId}
+PATCH	/settings/allow-list/{entryId}
+DELETE	/settings/allow-list/{entryId}
+
+GET		/settings/block-list
+POST	/settings/block-list
+GET		/settings/block-list/{entryId}
+PATCH	/settings/block-list/{entryId}
+DELETE	/settings/block-list/{entryId}
+
+Entries are a sender address, a domain or an IP address (or CIDR range).  An entry with a
+mailbox id applies to that mailbox only, otherwise it applies to the whole tenant.
+*/
+
+// SenderList is the list an entry is kept in.
+type SenderList string
+
+const (
+	SenderAllowList SenderList = "allow"
+	SenderBlockList SenderList = "block"
+)
+
+var (
+	senderDomainRegex = regexp.MustCompile(`(?i)^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	alertFromRegex    = regexp.MustCompile(`(?i)\b(?:from|sent by):?\s*<?([^\s<>"'(),;]+@[^\s<>"'(),;]+[a-z0-9])`)
+)
+
+// GetSenderListEntries returns the entries of a list.
+// Allowed query params: entryType, mailboxId, search, page, pageSize, pageTotal
+func (c *Client) GetSenderListEntries(ctx context.Context, tenant TenantsResponseItem, list SenderList, queryParams map[string]string) (SenderListEntries, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/settings/{allow|block}-list
+
+	path, err := list.path()
+	if err != nil {
+		return SenderListEntries{}, err
+	}
+
+	var all SenderListEntries
+	err = c.tenantPages(ctx, tenant, path, queryParams, func(b []byte) (Pages, error) {
+		sle, err := UnmarshalSenderListEntries(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, sle.Items...)
+		all.Pages = sle.Pages
+		return sle.Pages, nil
+	})
+	if err != nil {
+		return SenderListEntries{}, err
+	}
+
+	return all, nil
+}
+
+// GetSenderListEntry returns one entry of a list by id.
+func (c *Client) GetSenderListEntry(ctx context.Context, tenant TenantsResponseItem, list SenderList, entryID string) (SenderListEntry, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/settings/{allow|block}-list/{entryId}
+
+	path, err := list.path()
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+	if _, err := uuid.Parse(entryID); err != nil {
+		return SenderListEntry{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", path, entryID), nil, nil)
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+
+	return UnmarshalSenderListEntry(b)
+}
+
+// AddSenderListEntry adds an entry to a list.  Entries already in the list for the same
+// mailbox are not posted again and ErrDuplicateItem is returned.
+func (c *Client) AddSenderListEntry(ctx context.Context, tenant TenantsResponseItem, list SenderList, slr SenderListEntryRequest) (SenderListEntry, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/settings/{allow|block}-list
+
+	path, err := list.path()
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+	if err := slr.Validate(); err != nil {
+		return SenderListEntry{}, err
+	}
+
+	existing, err := c.GetSenderListEntries(ctx, tenant, list, nil)
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+	if dup, ok := existing.Find(slr.Type, slr.Value, slr.MailboxID); ok {
+		return dup, fmt.Errorf("%w: %s", ErrDuplicateItem, dup.ID)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", path, nil, slr)
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+
+	return UnmarshalSenderListEntry(b)
+}
+
+// UpdateSenderListEntry changes an entry of a list.
+func (c *Client) UpdateSenderListEntry(ctx context.Context, tenant TenantsResponseItem, list SenderList, entryID string, slr SenderListEntryRequest) (SenderListEntry, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/settings/{allow|block}-list/{entryId}
+
+	path, err := list.path()
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+	if _, err := uuid.Parse(entryID); err != nil {
+		return SenderListEntry{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+	if err := slr.Validate(); err != nil {
+		return SenderListEntry{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/%s", path, entryID), nil, slr)
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+
+	return UnmarshalSenderListEntry(b)
+}
+
+// DeleteSenderListEntry removes an entry from a list.
+func (c *Client) DeleteSenderListEntry(ctx context.Context, tenant TenantsResponseItem, list SenderList, entryID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/email/v1/settings/{allow|block}-list/{entryId}
+
+	path, err := list.path()
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+	if _, err := uuid.Parse(entryID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/%s", path, entryID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// ImportSenderListCSV adds the entries read from r to a list, see ParseSenderListCSV for the
+// format.  Invalid entries are skipped with their validation error and entries already in the
+// list with ErrDuplicateItem.  One result is returned per row so a failed row does not stop
+// the rest of the import; only a file that cannot be read as CSV fails the whole import.
+func (c *Client) ImportSenderListCSV(ctx context.Context, tenant TenantsResponseItem, list SenderList, r io.Reader) ([]SenderListImportResult, error) {
+
+	path, err := list.path()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseSenderListCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.GetSenderListEntries(ctx, tenant, list, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SenderListImportResult, 0, len(rows))
+	for _, row := range rows {
+		slr := row.request
+		res := SenderListImportResult{Row: row.line, Request: slr, Err: row.err}
+		if res.Err != nil {
+			results = append(results, res)
+			continue
+		}
+		if dup, ok := existing.Find(slr.Type, slr.Value, slr.MailboxID); ok {
+			res.Err = fmt.Errorf("%w: %s", ErrDuplicateItem, dup.ID)
+			results = append(results, res)
+			continue
+		}
+
+		var b []byte
+		b, res.Err = c.tenantRequest(ctx, tenant, "POST", path, nil, slr)
+		if res.Err == nil {
+			res.Entry, res.Err = UnmarshalSenderListEntry(b)
+		}
+		if res.Err == nil {
+			existing.Items = append(existing.Items, res.Entry)
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// ParseSenderListCSV reads sender list entries from CSV.  The first row is a header naming
+// the columns, in any order: value (required), type, comment and mailboxId.  When type is
+// left empty it is worked out from the value.
+func ParseSenderListCSV(r io.Reader) ([]SenderListEntryRequest, error) {
+
+	rows, err := parseSenderListCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]SenderListEntryRequest, 0, len(rows))
+	for _, row := range rows {
+		if row.err != nil {
+			return nil, fmt.Errorf("invalid sender list entry on csv line %d: %w", row.line, row.err)
+		}
+		entries = append(entries, row.request)
+	}
+
+	return entries, nil
+}
+
+// senderListRow is one data row of a sender list csv.  err is set when the entry is invalid.
+type senderListRow struct {
+	line    int
+	request SenderListEntryRequest
+	err     error
+}
+
+// parseSenderListCSV reads every row of a sender list csv, keeping invalid entries with their
+// error.  It fails only when the csv itself cannot be read.
+func parseSenderListCSV(r io.Reader) ([]senderListRow, error) {
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["value"]; !ok {
+		return nil, errors.New("csv header must include a value column")
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := cols[strings.ToLower(name)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []senderListRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d: %w", line, err)
+		}
+
+		value := cell(record, "value")
+		entryType := SenderEntryType(strings.ToLower(cell(record, "type")))
+		if entryType == "" {
+			entryType = DetectSenderEntryType(value)
+		}
+		slr := SenderListEntryRequest{
+			Type:      entryType,
+			Value:     value,
+			Comment:   cell(record, "comment"),
+			MailboxID: cell(record, "mailboxId"),
+		}
+
+		rows = append(rows, senderListRow{line: line, request: slr, err: slr.Validate()})
+	}
+
+	return rows, nil
+}
+
+// BlockSenderFromAlert adds the sender of a phishing or email gateway alert to the block list.
+// See SenderFromAlert for how the sender is found.
+func (c *Client) BlockSenderFromAlert(ctx context.Context, tenant TenantsResponseItem, alert AlertItem, comment string) (SenderListEntry, error) {
+
+	sender, err := SenderFromAlert(alert)
+	if err != nil {
+		return SenderListEntry{}, err
+	}
+
+	if comment == "" {
+		comment = fmt.Sprintf("blocked from alert %s", alert.ID)
+	}
+	return c.AddSenderListEntry(ctx, tenant, SenderBlockList, SenderListEntryRequest{Type: SenderAddress, Value: sender, Comment: comment})
+}
+
+// SenderFromAlert returns the sender address of a PhishThreat or emailGateway alert.  Alerts
+// carry no sender field, so it is read from the description: the address following "from" or
+// "sent by".  A description with no address marked as the sender is an error rather than a
+// guess, since a lone address may as well be the recipient.
+func SenderFromAlert(alert AlertItem) (string, error) {
+
+	if alert.Product != PhishThreat && alert.Product != EmailGateway {
+		return "", ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "alert.Product"}, Value: alert.Product}
+	}
+
+	if m := alertFromRegex.FindStringSubmatch(alert.Description); m != nil {
+		return strings.ToLower(m[1]), nil
+	}
+
+	return "", ErrMissingInput{Argument: "alert.Description sender"}
+}
+
+// DetectSenderEntryType works out whether value is an address, IP address or domain.  It
+// returns an empty type for anything else.
+func DetectSenderEntryType(value string) SenderEntryType {
+	switch {
+	case strings.Contains(value, "@"):
+		return SenderAddress
+	case net.ParseIP(value) != nil:
+		return SenderIP
+	case strings.Contains(value, "/"):
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			return SenderIP
+		}
+	case senderDomainRegex.MatchString(value):
+		return SenderDomain
+	}
+	return ""
+}
+
+// Validate checks the value is a well formed entry of its type.  Addresses must be bare, as
+// in user@example.com, domains may start with a *. wildcard and IPs may be CIDR ranges.
+func (slr SenderListEntryRequest) Validate() error {
+
+	if slr.Value == "" {
+		return ErrMissingInput{Argument: "Value"}
+	}
+
+	invalid := ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Value"}, Value: slr.Value}
+	switch slr.Type {
+	case SenderAddress:
+		addr, err := mail.ParseAddress(slr.Value)
+		if err != nil || addr.Address != slr.Value {
+			return invalid
+		}
+	case SenderDomain:
+		if !senderDomainRegex.MatchString(slr.Value) {
+			return invalid
+		}
+	case SenderIP:
+		if net.ParseIP(slr.Value) == nil {
+			if _, _, err := net.ParseCIDR(slr.Value); err != nil {
+				return invalid
+			}
+		}
+	default:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Type"}, Value: slr.Type}
+	}
+
+	if slr.MailboxID != "" {
+		if _, err := uuid.Parse(slr.MailboxID); err != nil {
+			return fmt.Errorf("%s: %w", ErrMailboxID, err)
+		}
+	}
+
+	return nil
+}
+
+// Find returns the entry of type et matching value for a mailbox, or tenant wide when
+// mailboxID is empty.  Values are compared without regard to case.
+func (sle SenderListEntries) Find(et SenderEntryType, value, mailboxID string) (SenderListEntry, bool) {
+	for _, e := range sle.Items {
+		if e.Type == et && strings.EqualFold(e.Value, value) && e.MailboxID == mailboxID {
+			return e, true
+		}
+	}
+	return SenderListEntry{}, false
+}
+
+func (list SenderList) path() (string, error) {
+	switch list {
+	case SenderAllowList, SenderBlockList:
+		return fmt.Sprintf("/email/v1/settings/%s-list", list), nil
+	default:
+		return "", ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "SenderList"}, Value: list}
+	}
+}
+
+func UnmarshalSenderListEntries(data []byte) (SenderListEntries, error) {
+	var r SenderListEntries
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SenderListEntries{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalSenderListEntry(data []byte) (SenderListEntry, error) {
+	var r SenderListEntry
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SenderListEntry{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type SenderEntryType string
+
+const (
+	SenderAddress SenderEntryType = "address"
+	SenderDomain  SenderEntryType = "domain"
+	SenderIP      SenderEntryType = "ip"
+)
+
+type SenderListEntries struct {
+	Items []SenderListEntry `json:"items"`
+	Pages Pages             `json:"pages"`
+}
+
+type SenderListEntry struct {
+	ID        string          `json:"id"`
+	Type      SenderEntryType `json:"type"`
+	Value     string          `json:"value"`
+	Comment   string          `json:"comment,omitempty"`
+	MailboxID string          `json:"mailboxId,omitempty"`
+	CreatedBy *ItemCreatedBy  `json:"createdBy,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt *time.Time      `json:"updatedAt,omitempty"`
+}
+
+type SenderListEntryRequest struct {
+	Type      SenderEntryType `json:"type"`
+	Value     string          `json:"value"`
+	Comment   string          `json:"comment,omitempty"`
+	MailboxID string          `json:"mailboxId,omitempty"`
+}
+
+// SenderListImportResult is the outcome of importing one csv row.  Row is the csv line number,
+// counting the header as line 1, as in the errors of ParseSenderListCSV.
+type SenderListImportResult struct {
+	Row     int
+	Request SenderListEntryRequest
+	Entry   SenderListEntry
+	Err     error
+}
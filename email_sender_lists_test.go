@@ -0,0 +1,130 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestParseSenderListCSV(t *testing.T) {
+	a := assert.New(t)
+
+	entries, err := ParseSenderListCSV(strings.NewReader(`value,type,comment,mailboxId
+bad@example.com,,known spammer,
+*.spam.example,domain,whole domain,
+203.0.113.0/24,,relay range,bc893b97-86a8-41aa-b65c-910e11505605
+`))
+	a.NoError(err)
+	a.Equal([]SenderListEntryRequest{
+		{Type: SenderAddress, Value: "bad@example.com", Comment: "known spammer"},
+		{Type: SenderDomain, Value: "*.spam.example", Comment: "whole domain"},
+		{Type: SenderIP, Value: "203.0.113.0/24", Comment: "relay range", MailboxID: "bc893b97-86a8-41aa-b65c-910e11505605"},
+	}, entries)
+
+	_, err = ParseSenderListCSV(strings.NewReader("value,type\nok@example.com,address\nBob <bob@example.com>,address\n"))
+	a.EqualError(err, "invalid sender list entry on csv line 3: "+SenderListEntryRequest{Type: SenderAddress, Value: "Bob <bob@example.com>"}.Validate().Error())
+	_, err = ParseSenderListCSV(strings.NewReader("value\nnot a sender\n"))
+	a.Error(err)
+	_, err = ParseSenderListCSV(strings.NewReader("address\nbob@example.com\n"))
+	a.Error(err)
+}
+
+func TestClient_ImportSenderListCSV(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var posted []string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/email/v1/settings/allow-list", req.URL.Path)
+			body := `{"items": [{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "type": "domain", "value": "partner.example"}], "pages": {"current": 1, "total": 1}}`
+			if req.Method == "POST" {
+				var slr SenderListEntryRequest
+				b, _ := ioutil.ReadAll(req.Body)
+				a.NoError(json.Unmarshal(b, &slr))
+				posted = append(posted, slr.Value)
+				body = `{"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504", "type": "address", "value": "` + slr.Value + `"}`
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	results, err := c.ImportSenderListCSV(context.Background(), tenant, SenderAllowList, strings.NewReader(`value
+not a sender
+partner.example
+good@partner.example
+`))
+	a.NoError(err)
+	a.Len(results, 3)
+	a.Equal([]int{2, 3, 4}, []int{results[0].Row, results[1].Row, results[2].Row})
+	a.Error(results[0].Err)
+	a.ErrorIs(results[1].Err, ErrDuplicateItem)
+	a.NoError(results[2].Err)
+	a.Equal("03b43abe-4f41-4734-b6d6-70b2fbdc2504", results[2].Entry.ID)
+	a.Equal([]string{"good@partner.example"}, posted)
+
+	_, err = c.ImportSenderListCSV(context.Background(), tenant, SenderAllowList, strings.NewReader("address\nbob@example.com\n"))
+	a.Error(err)
+}
+
+func TestSenderFromAlert(t *testing.T) {
+	a := assert.New(t)
+
+	sender, err := SenderFromAlert(AlertItem{Product: PhishThreat, Description: "Phishing email from Attacker@Evil.example to alice@corp.example was reported"})
+	a.NoError(err)
+	a.Equal("attacker@evil.example", sender)
+
+	sender, err = SenderFromAlert(AlertItem{Product: EmailGateway, Description: "Malware quarantined in a message sent by spoof@evil.example."})
+	a.NoError(err)
+	a.Equal("spoof@evil.example", sender)
+
+	_, err = SenderFromAlert(AlertItem{Product: EmailGateway, Description: "Message for alice@corp.example and bob@corp.example quarantined"})
+	a.Error(err)
+	_, err = SenderFromAlert(AlertItem{Product: EmailGateway, Description: "Malware quarantined in a message to alice@corp.example"})
+	a.Error(err)
+	_, err = SenderFromAlert(AlertItem{Product: EmailGateway, Description: "Message quarantined"})
+	a.Error(err)
+	_, err = SenderFromAlert(AlertItem{Product: Endpoint, Description: "email from a@b.example"})
+	a.Error(err)
+}
+
+func TestClient_BlockSenderFromAlert(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var posted []SenderListEntryRequest
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/email/v1/settings/block-list", req.URL.Path)
+			body := `{"items": [{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "type": "address", "value": "Known@Evil.example", "createdAt": "2021-05-05T11:47:30.148Z"}], "pages": {"current": 1, "total": 1}}`
+			if req.Method == "POST" {
+				var slr SenderListEntryRequest
+				b, _ := ioutil.ReadAll(req.Body)
+				a.NoError(json.Unmarshal(b, &slr))
+				posted = append(posted, slr)
+				body = `{"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504", "type": "address", "value": "` + slr.Value + `", "createdAt": "2021-05-05T11:47:30.148Z"}`
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	entry, err := c.BlockSenderFromAlert(context.Background(), tenant, AlertItem{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Product: PhishThreat, Description: "Phish from new@evil.example"}, "")
+	a.NoError(err)
+	a.Equal("03b43abe-4f41-4734-b6d6-70b2fbdc2504", entry.ID)
+	a.Equal([]SenderListEntryRequest{{Type: SenderAddress, Value: "new@evil.example", Comment: "blocked from alert bc893b97-86a8-41aa-b65c-910e11505605"}}, posted)
+
+	entry, err = c.BlockSenderFromAlert(context.Background(), tenant, AlertItem{Product: PhishThreat, Description: "Phish from known@evil.example"}, "again")
+	a.Error(err)
+	a.Equal("d2ba043d-7fcd-4158-a861-1ec2c01f3d14", entry.ID)
+	a.Len(posted, 1)
+}
@@ -0,0 +1,119 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Adaptive attack protection for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/endpoints/{endpointId}/adaptive-attack-protection
+POST	/endpoints/{endpointId}/adaptive-attack-protection
+*/
+
+// GetAdaptiveAttackProtection returns whether adaptive attack protection is active on an endpoint.
+func (c *Client) GetAdaptiveAttackProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string) (AdaptiveAttackProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/adaptive-attack-protection
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return AdaptiveAttackProtection{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/endpoints/%s/adaptive-attack-protection", endpointID), nil, nil)
+	if err != nil {
+		return AdaptiveAttackProtection{}, err
+	}
+
+	return UnmarshalAdaptiveAttackProtection(b)
+}
+
+// EnableAdaptiveAttackProtection forces adaptive attack protection on for an endpoint.
+func (c *Client) EnableAdaptiveAttackProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string) (AdaptiveAttackProtection, error) {
+	return c.setAdaptiveAttackProtection(ctx, tenant, endpointID, true)
+}
+
+// DisableAdaptiveAttackProtection turns forced adaptive attack protection off for an endpoint.
+// Central may still turn it on again by itself if it detects an attack.
+func (c *Client) DisableAdaptiveAttackProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string) (AdaptiveAttackProtection, error) {
+	return c.setAdaptiveAttackProtection(ctx, tenant, endpointID, false)
+}
+
+func (c *Client) setAdaptiveAttackProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string, enabled bool) (AdaptiveAttackProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/adaptive-attack-protection
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return AdaptiveAttackProtection{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoints/%s/adaptive-attack-protection", endpointID), nil, AdaptiveAttackProtectionRequest{Enabled: enabled})
+	if err != nil {
+		return AdaptiveAttackProtection{}, err
+	}
+
+	return UnmarshalAdaptiveAttackProtection(b)
+}
+
+// GetAdaptiveAttackProtectionReport checks every endpoint in eps, at most concurrency at a time,
+// and returns the endpoints adaptive attack protection is active on.  Endpoints that could not
+// be checked are returned in Failed with their error.
+func (c *Client) GetAdaptiveAttackProtectionReport(ctx context.Context, tenant TenantsResponseItem, eps Endpoints, concurrency int) AdaptiveAttackProtectionReport {
+
+	statuses := make([]AdaptiveAttackProtection, len(eps.Item))
+	errs := runForEndpoints(ctx, eps, concurrency, func(ctx context.Context, i int, ep EndpointItem) error {
+		var err error
+		statuses[i], err = c.GetAdaptiveAttackProtection(ctx, tenant, ep.ID)
+		return err
+	})
+
+	var report AdaptiveAttackProtectionReport
+	for i, ep := range eps.Item {
+		switch {
+		case errs[i] != nil:
+			report.Failed = append(report.Failed, EndpointBulkResult{EndpointID: ep.ID, Hostname: ep.Hostname, Err: errs[i]})
+		case statuses[i].Enabled:
+			report.Active = append(report.Active, EndpointAdaptiveAttackProtection{Endpoint: ep, Status: statuses[i]})
+		default:
+			report.InactiveCount++
+		}
+	}
+
+	return report
+}
+
+func UnmarshalAdaptiveAttackProtection(data []byte) (AdaptiveAttackProtection, error) {
+	var r AdaptiveAttackProtection
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return AdaptiveAttackProtection{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type AdaptiveAttackProtection struct {
+	Enabled   bool       `json:"enabled"`
+	Source    string     `json:"source,omitempty"`
+	EnabledAt *time.Time `json:"enabledAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type AdaptiveAttackProtectionRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type EndpointAdaptiveAttackProtection struct {
+	Endpoint EndpointItem
+	Status   AdaptiveAttackProtection
+}
+
+type AdaptiveAttackProtectionReport struct {
+	Active        []EndpointAdaptiveAttackProtection
+	InactiveCount int
+	Failed        []EndpointBulkResult
+}
@@ -0,0 +1,77 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_GetAdaptiveAttackProtectionReport(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			id := strings.Split(req.URL.Path, "/")[4]
+			switch id {
+			case "bc893b97-86a8-41aa-b65c-910e11505605":
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"enabled": true, "source": "automatic", "enabledAt": "2021-05-05T11:47:30.148Z"}`))}
+			case "03b43abe-4f41-4734-b6d6-70b2fbdc2504":
+				return &http.Response{StatusCode: 500, Body: ioutil.NopCloser(bytes.NewBufferString(`{"error": "internal"}`))}
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"enabled": false}`))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	eps := Endpoints{Item: []EndpointItem{
+		{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Hostname: "WIN10-01"},
+		{ID: "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", Hostname: "WIN10-02"},
+		{ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504", Hostname: "SRV-01"},
+		{ID: "not an endpoint", Hostname: "BROKEN"},
+		{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", Hostname: "WIN10-03"},
+	}}
+
+	report := c.GetAdaptiveAttackProtectionReport(context.Background(), tenant, eps, 2)
+	a.Len(report.Active, 1)
+	a.Equal("WIN10-01", report.Active[0].Endpoint.Hostname)
+	a.Equal("automatic", report.Active[0].Status.Source)
+	a.Equal(2, report.InactiveCount)
+	a.Len(report.Failed, 2)
+	a.Equal("SRV-01", report.Failed[0].Hostname)
+	a.Error(report.Failed[0].Err)
+	a.Equal("not an endpoint", report.Failed[1].EndpointID)
+	a.Error(report.Failed[1].Err)
+	a.LessOrEqual(maxInFlight, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = c.GetAdaptiveAttackProtectionReport(ctx, tenant, eps, 2)
+	a.Empty(report.Active)
+	a.Len(report.Failed, len(eps.Item))
+	a.Equal(context.Canceled, report.Failed[0].Err)
+}
@@ -0,0 +1,309 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Global allowed and blocked items for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/settings/allowed-items
+POST	/settings/allowed-items
+GET		/settings/allowed-items/{allowedItemId}
+DELETE	/settings/allowed-items/{allowedItemId}
+
+GET		/settings/blocked-items
+POST	/settings/blocked-items
+GET		/settings/blocked-items/{blockedItemId}
+DELETE	/settings/blocked-items/{blockedItemId}
+*/
+
+const (
+	allowedItemsPath = "/endpoint/v1/settings/allowed-items"
+	blockedItemsPath = "/endpoint/v1/settings/blocked-items"
+)
+
+var sha256Regex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// GetAllowedItems returns every globally allowed item of a tenant.
+func (c *Client) GetAllowedItems(ctx context.Context, tenant TenantsResponseItem) (SettingsItems, error) {
+	return c.getSettingsItems(ctx, tenant, allowedItemsPath)
+}
+
+// GetAllowedItem returns one allowed item by id.
+func (c *Client) GetAllowedItem(ctx context.Context, tenant TenantsResponseItem, itemID string) (SettingsItem, error) {
+	return c.getSettingsItem(ctx, tenant, allowedItemsPath, itemID)
+}
+
+// AddAllowedItem allows an application tenant wide by SHA256, path or certificate signer.
+// Items already allowed are not posted again and ErrDuplicateItem is returned.
+func (c *Client) AddAllowedItem(ctx context.Context, tenant TenantsResponseItem, asi AddSettingsItemRequest) (SettingsItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/allowed-items
+	return c.addSettingsItem(ctx, tenant, allowedItemsPath, asi)
+}
+
+// DeleteAllowedItem removes an allowed item.
+func (c *Client) DeleteAllowedItem(ctx context.Context, tenant TenantsResponseItem, itemID string) (DeletedResponse, error) {
+	return c.deleteSettingsItem(ctx, tenant, allowedItemsPath, itemID)
+}
+
+// GetBlockedItems returns every globally blocked item of a tenant.
+func (c *Client) GetBlockedItems(ctx context.Context, tenant TenantsResponseItem) (SettingsItems, error) {
+	return c.getSettingsItems(ctx, tenant, blockedItemsPath)
+}
+
+// GetBlockedItem returns one blocked item by id.
+func (c *Client) GetBlockedItem(ctx context.Context, tenant TenantsResponseItem, itemID string) (SettingsItem, error) {
+	return c.getSettingsItem(ctx, tenant, blockedItemsPath, itemID)
+}
+
+// AddBlockedItem blocks a file tenant wide.  Central only blocks by SHA256.
+// Items already blocked are not posted again and ErrDuplicateItem is returned.
+func (c *Client) AddBlockedItem(ctx context.Context, tenant TenantsResponseItem, asi AddSettingsItemRequest) (SettingsItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/blocked-items
+
+	if asi.Type != SHA256Item {
+		return SettingsItem{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Type"}, Value: asi.Type}
+	}
+	return c.addSettingsItem(ctx, tenant, blockedItemsPath, asi)
+}
+
+// DeleteBlockedItem removes a blocked item.
+func (c *Client) DeleteBlockedItem(ctx context.Context, tenant TenantsResponseItem, itemID string) (DeletedResponse, error) {
+	return c.deleteSettingsItem(ctx, tenant, blockedItemsPath, itemID)
+}
+
+func (c *Client) getSettingsItems(ctx context.Context, tenant TenantsResponseItem, path string) (SettingsItems, error) {
+
+	var all SettingsItems
+	err := c.tenantPages(ctx, tenant, path, nil, func(b []byte) (Pages, error) {
+		items, err := UnmarshalSettingsItems(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, items.Items...)
+		all.Pages = items.Pages
+		return items.Pages, nil
+	})
+	if err != nil {
+		return SettingsItems{}, err
+	}
+
+	return all, nil
+}
+
+func (c *Client) getSettingsItem(ctx context.Context, tenant TenantsResponseItem, path, itemID string) (SettingsItem, error) {
+
+	if _, err := uuid.Parse(itemID); err != nil {
+		return SettingsItem{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", path, itemID), nil, nil)
+	if err != nil {
+		return SettingsItem{}, err
+	}
+
+	return UnmarshalSettingsItem(b)
+}
+
+func (c *Client) addSettingsItem(ctx context.Context, tenant TenantsResponseItem, path string, asi AddSettingsItemRequest) (SettingsItem, error) {
+
+	if err := asi.Validate(); err != nil {
+		return SettingsItem{}, err
+	}
+
+	existing, err := c.getSettingsItems(ctx, tenant, path)
+	if err != nil {
+		return SettingsItem{}, err
+	}
+	if dup, ok := existing.Find(asi.Type, asi.Value()); ok {
+		return dup, fmt.Errorf("%w: %s", ErrDuplicateItem, dup.ID)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", path, nil, asi)
+	if err != nil {
+		return SettingsItem{}, err
+	}
+
+	return UnmarshalSettingsItem(b)
+}
+
+func (c *Client) deleteSettingsItem(ctx context.Context, tenant TenantsResponseItem, path, itemID string) (DeletedResponse, error) {
+
+	if _, err := uuid.Parse(itemID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/%s", path, itemID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// ValidateSHA256 checks that s is a hex encoded SHA256 hash.
+func ValidateSHA256(s string) error {
+	if !sha256Regex.MatchString(s) {
+		return fmt.Errorf("%s: %q", ErrInvalidSHA256, s)
+	}
+	return nil
+}
+
+// NewSHA256Item returns a request to allow or block a file by its SHA256.
+func NewSHA256Item(sha256, fileName, comment string) AddSettingsItemRequest {
+	return AddSettingsItemRequest{
+		Type:       SHA256Item,
+		Properties: SettingsItemProperties{SHA256: strings.ToLower(sha256), FileName: fileName},
+		Comment:    comment,
+	}
+}
+
+// NewPathItem returns a request to allow an application by its path.
+func NewPathItem(path, comment string) AddSettingsItemRequest {
+	return AddSettingsItemRequest{
+		Type:       PathItem,
+		Properties: SettingsItemProperties{Path: path},
+		Comment:    comment,
+	}
+}
+
+// NewCertificateSignerItem returns a request to allow applications signed by signer.
+func NewCertificateSignerItem(signer, comment string) AddSettingsItemRequest {
+	return AddSettingsItemRequest{
+		Type:       CertificateSignerItem,
+		Properties: SettingsItemProperties{CertificateSigner: signer},
+		Comment:    comment,
+	}
+}
+
+// Validate checks that the property for the item type is set, and that hashes are SHA256.
+func (asi AddSettingsItemRequest) Validate() error {
+
+	if asi.Comment == "" {
+		return ErrMissingInput{Argument: "Comment"}
+	}
+
+	switch asi.Type {
+	case SHA256Item:
+		if err := ValidateSHA256(asi.Properties.SHA256); err != nil {
+			return err
+		}
+	case PathItem:
+		if asi.Properties.Path == "" {
+			return ErrMissingInput{Argument: "Properties.Path"}
+		}
+	case CertificateSignerItem:
+		if asi.Properties.CertificateSigner == "" {
+			return ErrMissingInput{Argument: "Properties.CertificateSigner"}
+		}
+	default:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Type"}, Value: asi.Type}
+	}
+
+	if asi.OriginEndpointID != "" {
+		if _, err := uuid.Parse(asi.OriginEndpointID); err != nil {
+			return fmt.Errorf("%s: %w", ErrEndpointID, err)
+		}
+	}
+
+	return nil
+}
+
+// Value returns the property the item is matched on.
+func (asi AddSettingsItemRequest) Value() string {
+	return asi.Properties.value(asi.Type)
+}
+
+// Find returns the item of type it matching value.  Values are compared without regard to case.
+func (si SettingsItems) Find(it ItemType, value string) (SettingsItem, bool) {
+	for _, i := range si.Items {
+		if i.Type == it && strings.EqualFold(i.Properties.value(i.Type), value) {
+			return i, true
+		}
+	}
+	return SettingsItem{}, false
+}
+
+func (p SettingsItemProperties) value(it ItemType) string {
+	switch it {
+	case SHA256Item:
+		return p.SHA256
+	case PathItem:
+		return p.Path
+	case CertificateSignerItem:
+		return p.CertificateSigner
+	}
+	return ""
+}
+
+func UnmarshalSettingsItems(data []byte) (SettingsItems, error) {
+	var r SettingsItems
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SettingsItems{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalSettingsItem(data []byte) (SettingsItem, error) {
+	var r SettingsItem
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SettingsItem{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// SettingsItems holds allowed or blocked items, which share the same shape.
+type SettingsItems struct {
+	Items []SettingsItem `json:"items"`
+	Pages Pages          `json:"pages"`
+}
+
+type SettingsItem struct {
+	ID             string                 `json:"id"`
+	Type           ItemType               `json:"type"`
+	Properties     SettingsItemProperties `json:"properties"`
+	Comment        string                 `json:"comment"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	CreatedBy      *ItemCreatedBy         `json:"createdBy,omitempty"`
+	OriginPerson   *ItemCreatedBy         `json:"originPerson,omitempty"`
+	OriginEndpoint *TenantEP              `json:"originEndpoint,omitempty"`
+}
+
+type SettingsItemProperties struct {
+	FileName          string `json:"fileName,omitempty"`
+	Path              string `json:"path,omitempty"`
+	SHA256            string `json:"sha256,omitempty"`
+	CertificateSigner string `json:"certificateSigner,omitempty"`
+}
+
+type ItemCreatedBy struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type AddSettingsItemRequest struct {
+	Type             ItemType               `json:"type"`
+	Properties       SettingsItemProperties `json:"properties"`
+	Comment          string                 `json:"comment"`
+	OriginEndpointID string                 `json:"originEndpointId,omitempty"`
+}
+
+type ItemType string
+
+const (
+	SHA256Item            ItemType = "sha256"
+	PathItem              ItemType = "path"
+	CertificateSignerItem ItemType = "certificateSigner"
+)
@@ -0,0 +1,109 @@
+package sophoscentral
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestAddSettingsItemRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		asi     AddSettingsItemRequest
+		wantErr bool
+	}{
+		{
+			name:    "sha256",
+			asi:     NewSHA256Item("A7C1F3E5D6B2C4A8E0F1D3B5C7A9E2F4D6B8C0A1E3F5D7B9C2A4E6F8D0B1C3E5", "tool.exe", "known good"),
+			wantErr: false,
+		},
+		{
+			name:    "short sha256",
+			asi:     NewSHA256Item("a7c1f3e5d6", "tool.exe", "known good"),
+			wantErr: true,
+		},
+		{
+			name:    "md5 is not sha256",
+			asi:     NewSHA256Item("d41d8cd98f00b204e9800998ecf8427e", "tool.exe", "known good"),
+			wantErr: true,
+		},
+		{
+			name: "sha256 with bad origin endpoint",
+			asi: AddSettingsItemRequest{Type: SHA256Item, Comment: "known good", OriginEndpointID: "not an endpoint",
+				Properties: SettingsItemProperties{SHA256: "A7C1F3E5D6B2C4A8E0F1D3B5C7A9E2F4D6B8C0A1E3F5D7B9C2A4E6F8D0B1C3E5"}},
+			wantErr: true,
+		},
+		{
+			name:    "path",
+			asi:     NewPathItem(`C:\Tools\tool.exe`, "known good"),
+			wantErr: false,
+		},
+		{
+			name:    "empty path",
+			asi:     NewPathItem("", "known good"),
+			wantErr: true,
+		},
+		{
+			name:    "certificate signer",
+			asi:     NewCertificateSignerItem("Example Corp", "known good"),
+			wantErr: false,
+		},
+		{
+			name:    "missing comment",
+			asi:     NewCertificateSignerItem("Example Corp", ""),
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			asi:     AddSettingsItemRequest{Type: "md5", Comment: "known good"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.asi.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_AddBlockedItem(t *testing.T) {
+	a := assert.New(t)
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	existing := `{
+		"items": [{
+			"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+			"type": "sha256",
+			"properties": {"sha256": "a7c1f3e5d6b2c4a8e0f1d3b5c7a9e2f4d6b8c0a1e3f5d7b9c2a4e6f8d0b1c3e5"},
+			"comment": "ransomware"
+		}],
+		"pages": {"current": 1, "total": 1}
+	}`
+
+	var posted bool
+	c := &Client{
+		logger: logrus.New(),
+		token:  &oauth2.Token{AccessToken: "access token"},
+		httpClient: httpClientWithRequestRecorder(200, existing, func(req *http.Request, body []byte) {
+			if req.Method == "POST" {
+				posted = true
+			}
+		}),
+	}
+
+	got, err := c.AddBlockedItem(context.Background(), tenant,
+		NewSHA256Item("A7C1F3E5D6B2C4A8E0F1D3B5C7A9E2F4D6B8C0A1E3F5D7B9C2A4E6F8D0B1C3E5", "", "ransomware"))
+	a.True(errors.Is(err, ErrDuplicateItem))
+	a.Equal("d2ba043d-7fcd-4158-a861-1ec2c01f3d14", got.ID)
+	a.False(posted)
+
+	_, err = c.AddBlockedItem(context.Background(), tenant, NewPathItem(`C:\Tools\tool.exe`, "blocked"))
+	a.Error(err)
+	a.False(posted)
+}
@@ -0,0 +1,458 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Global exclusions for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/settings/exclusions/scanning
+POST	/settings/exclusions/scanning
+GET		/settings/exclusions/scanning/{exclusionId}
+PATCH	/settings/exclusions/scanning/{exclusionId}
+DELETE	/settings/exclusions/scanning/{exclusionId}
+
+GET		/settings/exclusions/isolation
+POST	/settings/exclusions/isolation
+PATCH	/settings/exclusions/isolation/{exclusionId}
+DELETE	/settings/exclusions/isolation/{exclusionId}
+
+GET		/settings/exclusions/intrusion-prevention
+POST	/settings/exclusions/intrusion-prevention
+PATCH	/settings/exclusions/intrusion-prevention/{exclusionId}
+DELETE	/settings/exclusions/intrusion-prevention/{exclusionId}
+*/
+
+const (
+	scanningExclusionsPath            = "/endpoint/v1/settings/exclusions/scanning"
+	isolationExclusionsPath           = "/endpoint/v1/settings/exclusions/isolation"
+	intrusionPreventionExclusionsPath = "/endpoint/v1/settings/exclusions/intrusion-prevention"
+)
+
+var (
+	windowsDriveRegex = regexp.MustCompile(`^[a-zA-Z]:`)
+	windowsPathRegex  = regexp.MustCompile(`^([a-zA-Z]:\\|\\\\[^\\]+\\|%[^%]+%\\|\*\\|[^\\:]+(\\|$))`)
+)
+
+// GetScanningExclusions returns every scanning exclusion of a tenant.
+// Allowed query params: type
+func (c *Client) GetScanningExclusions(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (ScanningExclusions, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exclusions/scanning
+
+	var all ScanningExclusions
+	err := c.tenantPages(ctx, tenant, scanningExclusionsPath, queryParams, func(b []byte) (Pages, error) {
+		se, err := UnmarshalScanningExclusions(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, se.Items...)
+		all.Pages = se.Pages
+		return se.Pages, nil
+	})
+	if err != nil {
+		return ScanningExclusions{}, err
+	}
+
+	return all, nil
+}
+
+// GetScanningExclusion returns one scanning exclusion by id.
+func (c *Client) GetScanningExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string) (ScanningExclusion, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exclusions/scanning/{exclusionId}
+
+	if _, err := uuid.Parse(exclusionID); err != nil {
+		return ScanningExclusion{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", scanningExclusionsPath, exclusionID), nil, nil)
+	if err != nil {
+		return ScanningExclusion{}, err
+	}
+
+	return UnmarshalScanningExclusion(b)
+}
+
+// AddScanningExclusion adds a tenant wide scanning exclusion.
+func (c *Client) AddScanningExclusion(ctx context.Context, tenant TenantsResponseItem, ser ScanningExclusionRequest) (ScanningExclusion, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exclusions/scanning
+
+	if err := ser.Validate(); err != nil {
+		return ScanningExclusion{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", scanningExclusionsPath, nil, ser)
+	if err != nil {
+		return ScanningExclusion{}, err
+	}
+
+	return UnmarshalScanningExclusion(b)
+}
+
+// UpdateScanningExclusion changes a scanning exclusion.
+func (c *Client) UpdateScanningExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string, ser ScanningExclusionRequest) (ScanningExclusion, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exclusions/scanning/{exclusionId}
+
+	if _, err := uuid.Parse(exclusionID); err != nil {
+		return ScanningExclusion{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+	if ser.ScanMode != "" && !ser.ScanMode.valid() {
+		return ScanningExclusion{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "ScanMode"}, Value: ser.ScanMode}
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/%s", scanningExclusionsPath, exclusionID), nil, ser)
+	if err != nil {
+		return ScanningExclusion{}, err
+	}
+
+	return UnmarshalScanningExclusion(b)
+}
+
+// DeleteScanningExclusion removes a scanning exclusion.
+func (c *Client) DeleteScanningExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string) (DeletedResponse, error) {
+	return c.deleteExclusion(ctx, tenant, scanningExclusionsPath, exclusionID)
+}
+
+// GetIsolationExclusions returns the traffic still allowed to and from isolated endpoints.
+func (c *Client) GetIsolationExclusions(ctx context.Context, tenant TenantsResponseItem) (NetworkExclusions, error) {
+	return c.getNetworkExclusions(ctx, tenant, isolationExclusionsPath)
+}
+
+// AddIsolationExclusion allows traffic to and from isolated endpoints.
+func (c *Client) AddIsolationExclusion(ctx context.Context, tenant TenantsResponseItem, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+	return c.addNetworkExclusion(ctx, tenant, isolationExclusionsPath, ner)
+}
+
+// UpdateIsolationExclusion changes an isolation exclusion.
+func (c *Client) UpdateIsolationExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+	return c.updateNetworkExclusion(ctx, tenant, isolationExclusionsPath, exclusionID, ner)
+}
+
+// DeleteIsolationExclusion removes an isolation exclusion.
+func (c *Client) DeleteIsolationExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string) (DeletedResponse, error) {
+	return c.deleteExclusion(ctx, tenant, isolationExclusionsPath, exclusionID)
+}
+
+// GetIntrusionPreventionExclusions returns the traffic intrusion prevention does not inspect.
+func (c *Client) GetIntrusionPreventionExclusions(ctx context.Context, tenant TenantsResponseItem) (NetworkExclusions, error) {
+	return c.getNetworkExclusions(ctx, tenant, intrusionPreventionExclusionsPath)
+}
+
+// AddIntrusionPreventionExclusion stops intrusion prevention inspecting some traffic.
+func (c *Client) AddIntrusionPreventionExclusion(ctx context.Context, tenant TenantsResponseItem, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+	return c.addNetworkExclusion(ctx, tenant, intrusionPreventionExclusionsPath, ner)
+}
+
+// UpdateIntrusionPreventionExclusion changes an intrusion prevention exclusion.
+func (c *Client) UpdateIntrusionPreventionExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+	return c.updateNetworkExclusion(ctx, tenant, intrusionPreventionExclusionsPath, exclusionID, ner)
+}
+
+// DeleteIntrusionPreventionExclusion removes an intrusion prevention exclusion.
+func (c *Client) DeleteIntrusionPreventionExclusion(ctx context.Context, tenant TenantsResponseItem, exclusionID string) (DeletedResponse, error) {
+	return c.deleteExclusion(ctx, tenant, intrusionPreventionExclusionsPath, exclusionID)
+}
+
+func (c *Client) getNetworkExclusions(ctx context.Context, tenant TenantsResponseItem, path string) (NetworkExclusions, error) {
+
+	var all NetworkExclusions
+	err := c.tenantPages(ctx, tenant, path, nil, func(b []byte) (Pages, error) {
+		ne, err := UnmarshalNetworkExclusions(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, ne.Items...)
+		all.Pages = ne.Pages
+		return ne.Pages, nil
+	})
+	if err != nil {
+		return NetworkExclusions{}, err
+	}
+
+	return all, nil
+}
+
+func (c *Client) addNetworkExclusion(ctx context.Context, tenant TenantsResponseItem, path string, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+
+	if err := ner.Validate(); err != nil {
+		return NetworkExclusion{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", path, nil, ner)
+	if err != nil {
+		return NetworkExclusion{}, err
+	}
+
+	return UnmarshalNetworkExclusion(b)
+}
+
+func (c *Client) updateNetworkExclusion(ctx context.Context, tenant TenantsResponseItem, path, exclusionID string, ner NetworkExclusionRequest) (NetworkExclusion, error) {
+
+	if _, err := uuid.Parse(exclusionID); err != nil {
+		return NetworkExclusion{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/%s", path, exclusionID), nil, ner)
+	if err != nil {
+		return NetworkExclusion{}, err
+	}
+
+	return UnmarshalNetworkExclusion(b)
+}
+
+func (c *Client) deleteExclusion(ctx context.Context, tenant TenantsResponseItem, path, exclusionID string) (DeletedResponse, error) {
+
+	if _, err := uuid.Parse(exclusionID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/%s", path, exclusionID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// NewPathExclusion returns a scanning exclusion for a file or folder on the given platform.
+// Windows paths are excluded with the path type and Linux and macOS paths with posixPath.
+func NewPathExclusion(platform Platform, path string, scanMode ScanMode, comment string) (ScanningExclusionRequest, error) {
+
+	if err := ValidateExclusionPath(platform, path); err != nil {
+		return ScanningExclusionRequest{}, err
+	}
+
+	et := PathExclusion
+	if platform != Windows {
+		et = PosixPathExclusion
+	}
+
+	return ScanningExclusionRequest{
+		Value:    path,
+		Type:     et,
+		ScanMode: scanMode,
+		Comment:  comment,
+	}, nil
+}
+
+// ValidateExclusionPath checks that path is written the way Central expects for platform.
+// Windows paths use backslashes and may start with a drive, a UNC share, an environment
+// variable, or be relative.  Linux and macOS paths use forward slashes.  Both may use the
+// * and ? wildcards.
+func ValidateExclusionPath(platform Platform, path string) error {
+
+	invalid := func(reason string) error {
+		return ErrInvalidInput{
+			ErrMissingInput: ErrMissingInput{BaseError: BaseError{Info: fmt.Sprintf("invalid %s exclusion path %q: %s", platform, path, reason)}, Argument: "path"},
+			Value:           path,
+		}
+	}
+
+	if path == "" {
+		return ErrMissingInput{Argument: "path"}
+	}
+	if strings.ContainsRune(path, 0) {
+		return invalid("contains a null character")
+	}
+
+	switch platform {
+	case Windows:
+		if strings.Contains(path, "/") {
+			return invalid("windows paths use backslashes")
+		}
+		if strings.ContainsAny(path, `<>"|`) {
+			return invalid(`contains one of <>"|`)
+		}
+		if strings.LastIndex(path, ":") > 1 || (strings.Contains(path, ":") && !windowsDriveRegex.MatchString(path)) {
+			return invalid("colon is only allowed after a drive letter")
+		}
+		if !windowsPathRegex.MatchString(path) {
+			return invalid("must start with a drive, share, environment variable or folder name")
+		}
+	case Linux, MacOS:
+		if windowsDriveRegex.MatchString(path) || strings.HasPrefix(path, `\\`) {
+			return invalid("looks like a windows path")
+		}
+		if strings.Contains(path, `\`) {
+			return invalid("posix paths use forward slashes")
+		}
+	default:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "platform"}, Value: platform}
+	}
+
+	return nil
+}
+
+// Validate checks the exclusion type and scan mode are known and a value is set.
+func (ser ScanningExclusionRequest) Validate() error {
+
+	if ser.Value == "" {
+		return ErrMissingInput{Argument: "Value"}
+	}
+	if !ser.Type.valid() {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Type"}, Value: ser.Type}
+	}
+	if ser.ScanMode != "" && !ser.ScanMode.valid() {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "ScanMode"}, Value: ser.ScanMode}
+	}
+
+	switch ser.Type {
+	case PathExclusion:
+		return ValidateExclusionPath(Windows, ser.Value)
+	case PosixPathExclusion:
+		return ValidateExclusionPath(Linux, ser.Value)
+	}
+
+	return nil
+}
+
+// Validate checks the direction is known and something to match on is set.
+func (ner NetworkExclusionRequest) Validate() error {
+
+	switch ner.Direction {
+	case "", Inbound, Outbound, BothDirections:
+	default:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Direction"}, Value: ner.Direction}
+	}
+	if len(ner.RemoteAddresses) == 0 && len(ner.LocalPorts) == 0 && len(ner.RemotePorts) == 0 {
+		return ErrMissingInput{Argument: "RemoteAddresses, LocalPorts or RemotePorts"}
+	}
+
+	return nil
+}
+
+func (et ExclusionType) valid() bool {
+	switch et {
+	case PathExclusion, PosixPathExclusion, VirtualPathExclusion, ProcessExclusion, WebExclusion,
+		PUAExclusion, AMSIExclusion, ExploitMitigationExclusion, DetectedExploitExclusion, BehavioralExclusion:
+		return true
+	}
+	return false
+}
+
+func (sm ScanMode) valid() bool {
+	switch sm {
+	case OnDemand, OnAccess, OnDemandAndOnAccess:
+		return true
+	}
+	return false
+}
+
+func UnmarshalScanningExclusions(data []byte) (ScanningExclusions, error) {
+	var r ScanningExclusions
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return ScanningExclusions{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalScanningExclusion(data []byte) (ScanningExclusion, error) {
+	var r ScanningExclusion
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return ScanningExclusion{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalNetworkExclusions(data []byte) (NetworkExclusions, error) {
+	var r NetworkExclusions
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return NetworkExclusions{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalNetworkExclusion(data []byte) (NetworkExclusion, error) {
+	var r NetworkExclusion
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return NetworkExclusion{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type ScanningExclusions struct {
+	Items []ScanningExclusion `json:"items"`
+	Pages Pages               `json:"pages"`
+}
+
+type ScanningExclusion struct {
+	ID          string        `json:"id"`
+	Value       string        `json:"value"`
+	Type        ExclusionType `json:"type"`
+	ScanMode    ScanMode      `json:"scanMode,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+}
+
+type ScanningExclusionRequest struct {
+	Value    string        `json:"value,omitempty"`
+	Type     ExclusionType `json:"type,omitempty"`
+	ScanMode ScanMode      `json:"scanMode,omitempty"`
+	Comment  string        `json:"comment,omitempty"`
+}
+
+// NetworkExclusions holds isolation or intrusion prevention exclusions, which share the same shape.
+type NetworkExclusions struct {
+	Items []NetworkExclusion `json:"items"`
+	Pages Pages              `json:"pages"`
+}
+
+type NetworkExclusion struct {
+	ID              string             `json:"id"`
+	Direction       ExclusionDirection `json:"direction,omitempty"`
+	RemoteAddresses []string           `json:"remoteAddresses,omitempty"`
+	LocalPorts      []string           `json:"localPorts,omitempty"`
+	RemotePorts     []string           `json:"remotePorts,omitempty"`
+	Comment         string             `json:"comment,omitempty"`
+}
+
+type NetworkExclusionRequest struct {
+	Direction       ExclusionDirection `json:"direction,omitempty"`
+	RemoteAddresses []string           `json:"remoteAddresses,omitempty"`
+	LocalPorts      []string           `json:"localPorts,omitempty"`
+	RemotePorts     []string           `json:"remotePorts,omitempty"`
+	Comment         string             `json:"comment,omitempty"`
+}
+
+type ExclusionType string
+
+const (
+	PathExclusion              ExclusionType = "path"
+	PosixPathExclusion         ExclusionType = "posixPath"
+	VirtualPathExclusion       ExclusionType = "virtualPath"
+	ProcessExclusion           ExclusionType = "process"
+	WebExclusion               ExclusionType = "web"
+	PUAExclusion               ExclusionType = "pua"
+	AMSIExclusion              ExclusionType = "amsi"
+	ExploitMitigationExclusion ExclusionType = "exploitMitigation"
+	DetectedExploitExclusion   ExclusionType = "detectedExploit"
+	BehavioralExclusion        ExclusionType = "behavioral"
+)
+
+type ScanMode string
+
+const (
+	OnDemand            ScanMode = "onDemand"
+	OnAccess            ScanMode = "onAccess"
+	OnDemandAndOnAccess ScanMode = "onDemandAndOnAccess"
+)
+
+type ExclusionDirection string
+
+const (
+	Inbound        ExclusionDirection = "inbound"
+	Outbound       ExclusionDirection = "outbound"
+	BothDirections ExclusionDirection = "both"
+)
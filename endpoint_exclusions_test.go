@@ -0,0 +1,59 @@
+package sophoscentral
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExclusionPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform Platform
+		path     string
+		wantErr  bool
+	}{
+		{name: "windows drive", platform: Windows, path: `C:\Build\out\`, wantErr: false},
+		{name: "windows wildcard", platform: Windows, path: `C:\Build\*.obj`, wantErr: false},
+		{name: "windows share", platform: Windows, path: `\\fileserver\builds\`, wantErr: false},
+		{name: "windows environment variable", platform: Windows, path: `%ProgramFiles%\Tool\`, wantErr: false},
+		{name: "windows file name", platform: Windows, path: `tool.exe`, wantErr: false},
+		{name: "windows relative folder", platform: Windows, path: `node_modules\`, wantErr: false},
+		{name: "windows forward slash", platform: Windows, path: `C:/Build/out`, wantErr: true},
+		{name: "windows misplaced colon", platform: Windows, path: `C:\Build:out`, wantErr: true},
+		{name: "windows invalid character", platform: Windows, path: `C:\Build\<out>`, wantErr: true},
+		{name: "linux absolute", platform: Linux, path: `/var/lib/docker/`, wantErr: false},
+		{name: "linux wildcard", platform: Linux, path: `/home/*/.cache/`, wantErr: false},
+		{name: "macos file name", platform: MacOS, path: `build.log`, wantErr: false},
+		{name: "linux windows path", platform: Linux, path: `C:\Build\`, wantErr: true},
+		{name: "linux backslash", platform: Linux, path: `/var\lib`, wantErr: true},
+		{name: "empty", platform: Linux, path: ``, wantErr: true},
+		{name: "unknown platform", platform: "android", path: `/sdcard`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateExclusionPath(tt.platform, tt.path); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExclusionPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPathExclusion(t *testing.T) {
+	a := assert.New(t)
+
+	ser, err := NewPathExclusion(Linux, "/var/lib/docker/", OnAccess, "container layers")
+	a.NoError(err)
+	a.Equal(ScanningExclusionRequest{Value: "/var/lib/docker/", Type: PosixPathExclusion, ScanMode: OnAccess, Comment: "container layers"}, ser)
+	a.NoError(ser.Validate())
+
+	ser, err = NewPathExclusion(Windows, `D:\Builds\`, OnDemandAndOnAccess, "")
+	a.NoError(err)
+	a.Equal(PathExclusion, ser.Type)
+
+	_, err = NewPathExclusion(Windows, "/var/lib/docker/", OnAccess, "")
+	a.Error(err)
+
+	a.Error(ScanningExclusionRequest{Value: "tool.exe", Type: ProcessExclusion, ScanMode: "always"}.Validate())
+	a.Error(ScanningExclusionRequest{Value: "tool.exe", Type: "hash"}.Validate())
+}
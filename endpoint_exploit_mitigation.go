@@ -0,0 +1,315 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Exploit mitigation settings for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/settings/exploit-mitigation/applications
+POST	/settings/exploit-mitigation/applications
+GET		/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+PATCH	/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+DELETE	/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+
+GET		/settings/exploit-mitigation/detected-exploits
+GET		/settings/exploit-mitigation/detected-exploits/{detectedExploitId}
+*/
+
+const (
+	exploitMitigationApplicationsPath = "/endpoint/v1/settings/exploit-mitigation/applications"
+	detectedExploitsPath              = "/endpoint/v1/settings/exploit-mitigation/detected-exploits"
+)
+
+// GetExploitMitigationApplications returns the applications protected by exploit mitigation.
+// Allowed query params: type, modified
+func (c *Client) GetExploitMitigationApplications(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (ExploitMitigationApplications, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/applications
+
+	var all ExploitMitigationApplications
+	err := c.tenantPages(ctx, tenant, exploitMitigationApplicationsPath, queryParams, func(b []byte) (Pages, error) {
+		apps, err := UnmarshalExploitMitigationApplications(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, apps.Items...)
+		all.Pages = apps.Pages
+		return apps.Pages, nil
+	})
+	if err != nil {
+		return ExploitMitigationApplications{}, err
+	}
+
+	return all, nil
+}
+
+// GetExploitMitigationApplication returns one protected application by id.
+func (c *Client) GetExploitMitigationApplication(ctx context.Context, tenant TenantsResponseItem, applicationID string) (ExploitMitigationApplication, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+
+	if _, err := uuid.Parse(applicationID); err != nil {
+		return ExploitMitigationApplication{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", exploitMitigationApplicationsPath, applicationID), nil, nil)
+	if err != nil {
+		return ExploitMitigationApplication{}, err
+	}
+
+	return UnmarshalExploitMitigationApplication(b)
+}
+
+// AddExploitMitigationApplication protects a custom application, identified by its paths.
+func (c *Client) AddExploitMitigationApplication(ctx context.Context, tenant TenantsResponseItem, paths []string) (ExploitMitigationApplication, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/applications
+
+	if len(paths) == 0 {
+		return ExploitMitigationApplication{}, ErrMissingInput{Argument: "paths"}
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", exploitMitigationApplicationsPath, nil, ExploitMitigationApplicationRequest{Paths: paths})
+	if err != nil {
+		return ExploitMitigationApplication{}, err
+	}
+
+	return UnmarshalExploitMitigationApplication(b)
+}
+
+// UpdateExploitMitigationApplication changes the paths or mitigations of a protected application.
+// Only custom applications can have their paths changed.
+func (c *Client) UpdateExploitMitigationApplication(ctx context.Context, tenant TenantsResponseItem, applicationID string, emr ExploitMitigationApplicationRequest) (ExploitMitigationApplication, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+
+	if _, err := uuid.Parse(applicationID); err != nil {
+		return ExploitMitigationApplication{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/%s", exploitMitigationApplicationsPath, applicationID), nil, emr)
+	if err != nil {
+		return ExploitMitigationApplication{}, err
+	}
+
+	return UnmarshalExploitMitigationApplication(b)
+}
+
+// SetApplicationMitigation turns one mitigation on or off for a protected application,
+// leaving its other mitigations as they are.
+func (c *Client) SetApplicationMitigation(ctx context.Context, tenant TenantsResponseItem, applicationID string, mitigation string, enabled bool) (ExploitMitigationApplication, error) {
+
+	app, err := c.GetExploitMitigationApplication(ctx, tenant, applicationID)
+	if err != nil {
+		return ExploitMitigationApplication{}, err
+	}
+
+	mitigations := make([]ApplicationMitigation, 0, len(app.Mitigations)+1)
+	found := false
+	for _, m := range app.Mitigations {
+		if strings.EqualFold(m.Name, mitigation) {
+			m.Enabled = enabled
+			found = true
+		}
+		mitigations = append(mitigations, m)
+	}
+	if !found {
+		mitigations = append(mitigations, ApplicationMitigation{Name: mitigation, Enabled: enabled})
+	}
+
+	return c.UpdateExploitMitigationApplication(ctx, tenant, applicationID, ExploitMitigationApplicationRequest{Mitigations: mitigations})
+}
+
+// DeleteExploitMitigationApplication stops protecting a custom application.
+func (c *Client) DeleteExploitMitigationApplication(ctx context.Context, tenant TenantsResponseItem, applicationID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/applications/{exploitMitigationApplicationId}
+
+	if _, err := uuid.Parse(applicationID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/%s", exploitMitigationApplicationsPath, applicationID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// GetDetectedExploits returns the exploits detected across the tenant.
+// Allowed query params: thumbprintIn, mitigation
+func (c *Client) GetDetectedExploits(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (DetectedExploits, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/detected-exploits
+
+	var all DetectedExploits
+	err := c.tenantPages(ctx, tenant, detectedExploitsPath, queryParams, func(b []byte) (Pages, error) {
+		de, err := UnmarshalDetectedExploits(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, de.Items...)
+		all.Pages = de.Pages
+		return de.Pages, nil
+	})
+	if err != nil {
+		return DetectedExploits{}, err
+	}
+
+	return all, nil
+}
+
+// GetDetectedExploit returns one detected exploit by id.
+func (c *Client) GetDetectedExploit(ctx context.Context, tenant TenantsResponseItem, detectedExploitID string) (DetectedExploit, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/exploit-mitigation/detected-exploits/{detectedExploitId}
+
+	if _, err := uuid.Parse(detectedExploitID); err != nil {
+		return DetectedExploit{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", detectedExploitsPath, detectedExploitID), nil, nil)
+	if err != nil {
+		return DetectedExploit{}, err
+	}
+
+	return UnmarshalDetectedExploit(b)
+}
+
+// ByThumbprint returns the detected exploit with the given thumbprint.
+func (de DetectedExploits) ByThumbprint(thumbprint string) (DetectedExploit, bool) {
+	for _, e := range de.Items {
+		if strings.EqualFold(e.Thumbprint, thumbprint) {
+			return e, true
+		}
+	}
+	return DetectedExploit{}, false
+}
+
+// JoinDetectedExploits pairs each detected exploit with the endpoints in eps it was seen on.
+// When an exploit only carries the last endpoint it was seen on, that endpoint is used.
+// Endpoints an exploit was seen on that are not in eps are left out.
+func JoinDetectedExploits(de DetectedExploits, eps Endpoints) []DetectedExploitHosts {
+
+	byID := make(map[string]EndpointItem, len(eps.Item))
+	for _, ep := range eps.Item {
+		byID[ep.ID] = ep
+	}
+
+	joined := make([]DetectedExploitHosts, 0, len(de.Items))
+	for _, e := range de.Items {
+		deh := DetectedExploitHosts{Exploit: e}
+		refs := e.Endpoints
+		if len(refs) == 0 && e.LastEndpoint != nil {
+			refs = []EndpointReference{*e.LastEndpoint}
+		}
+		for _, ref := range refs {
+			if ep, ok := byID[ref.ID]; ok {
+				deh.Endpoints = append(deh.Endpoints, ep)
+			}
+		}
+		joined = append(joined, deh)
+	}
+
+	return joined
+}
+
+func UnmarshalExploitMitigationApplications(data []byte) (ExploitMitigationApplications, error) {
+	var r ExploitMitigationApplications
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return ExploitMitigationApplications{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalExploitMitigationApplication(data []byte) (ExploitMitigationApplication, error) {
+	var r ExploitMitigationApplication
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return ExploitMitigationApplication{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalDetectedExploits(data []byte) (DetectedExploits, error) {
+	var r DetectedExploits
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return DetectedExploits{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalDetectedExploit(data []byte) (DetectedExploit, error) {
+	var r DetectedExploit
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return DetectedExploit{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type ExploitMitigationApplications struct {
+	Items []ExploitMitigationApplication `json:"items"`
+	Pages Pages                          `json:"pages"`
+}
+
+type ExploitMitigationApplication struct {
+	ID          string                           `json:"id"`
+	Name        string                           `json:"name"`
+	Category    string                           `json:"category"`
+	Type        ExploitMitigationApplicationType `json:"type"`
+	Paths       []string                         `json:"paths"`
+	Mitigations []ApplicationMitigation          `json:"mitigations,omitempty"`
+}
+
+type ApplicationMitigation struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type ExploitMitigationApplicationRequest struct {
+	Paths       []string                `json:"paths,omitempty"`
+	Mitigations []ApplicationMitigation `json:"mitigations,omitempty"`
+}
+
+type ExploitMitigationApplicationType string
+
+const (
+	DetectedApplication ExploitMitigationApplicationType = "detected"
+	CustomApplication   ExploitMitigationApplicationType = "custom"
+)
+
+type DetectedExploits struct {
+	Items []DetectedExploit `json:"items"`
+	Pages Pages             `json:"pages"`
+}
+
+type DetectedExploit struct {
+	ID           string              `json:"id"`
+	Thumbprint   string              `json:"thumbprint"`
+	Count        int                 `json:"count"`
+	Description  string              `json:"description"`
+	Mitigation   string              `json:"mitigation"`
+	FirstSeenAt  time.Time           `json:"firstSeenAt"`
+	LastSeenAt   time.Time           `json:"lastSeenAt"`
+	LastUser     *ItemCreatedBy      `json:"lastUser,omitempty"`
+	LastEndpoint *EndpointReference  `json:"lastEndpoint,omitempty"`
+	Endpoints    []EndpointReference `json:"endpoints,omitempty"`
+}
+
+type EndpointReference struct {
+	ID       string `json:"id"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// DetectedExploitHosts is a detected exploit and the endpoints it was seen on.
+type DetectedExploitHosts struct {
+	Exploit   DetectedExploit
+	Endpoints []EndpointItem
+}
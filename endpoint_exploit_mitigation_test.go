@@ -0,0 +1,71 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestJoinDetectedExploits(t *testing.T) {
+	a := assert.New(t)
+
+	de := DetectedExploits{Items: []DetectedExploit{
+		{ID: "e1", Thumbprint: "aa", Endpoints: []EndpointReference{{ID: "bc893b97-86a8-41aa-b65c-910e11505605"}, {ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504"}}},
+		{ID: "e2", Thumbprint: "bb", LastEndpoint: &EndpointReference{ID: "d2ba043d-7fcd-4158-a861-1ec2c01f3d14"}},
+		{ID: "e3", Thumbprint: "cc", LastEndpoint: &EndpointReference{ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504"}},
+	}}
+	eps := Endpoints{Item: []EndpointItem{
+		{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Hostname: "WIN10-01"},
+		{ID: "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", Hostname: "SRV-01"},
+	}}
+
+	joined := JoinDetectedExploits(de, eps)
+	a.Len(joined, 3)
+	a.Equal("e1", joined[0].Exploit.ID)
+	a.Equal([]EndpointItem{eps.Item[0]}, joined[0].Endpoints)
+	a.Equal([]EndpointItem{eps.Item[1]}, joined[1].Endpoints)
+	a.Empty(joined[2].Endpoints)
+
+	e, ok := de.ByThumbprint("BB")
+	a.True(ok)
+	a.Equal("e2", e.ID)
+}
+
+func TestClient_SetApplicationMitigation(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var patched ExploitMitigationApplicationRequest
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/endpoint/v1/settings/exploit-mitigation/applications/bc893b97-86a8-41aa-b65c-910e11505605", req.URL.Path)
+			body := `{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "Browser", "type": "detected",
+				"mitigations": [{"name": "heapSpray", "enabled": true}, {"name": "nullPage", "enabled": true}]}`
+			if req.Method == "PATCH" {
+				b, _ := ioutil.ReadAll(req.Body)
+				a.NoError(json.Unmarshal(b, &patched))
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	_, err := c.SetApplicationMitigation(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", "HeapSpray", false)
+	a.NoError(err)
+	a.Nil(patched.Paths)
+	a.Equal([]ApplicationMitigation{{Name: "heapSpray", Enabled: false}, {Name: "nullPage", Enabled: true}}, patched.Mitigations)
+
+	_, err = c.SetApplicationMitigation(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", "stackPivot", true)
+	a.NoError(err)
+	a.Equal([]ApplicationMitigation{{Name: "heapSpray", Enabled: true}, {Name: "nullPage", Enabled: true}, {Name: "stackPivot", Enabled: true}}, patched.Mitigations)
+
+	_, err = c.SetApplicationMitigation(context.Background(), tenant, "not an id", "heapSpray", true)
+	a.Error(err)
+}
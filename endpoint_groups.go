@@ -0,0 +1,289 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Endpoint groups for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/endpoint-groups
+POST	/endpoint-groups
+GET		/endpoint-groups/{groupId}
+PATCH	/endpoint-groups/{groupId}
+DELETE	/endpoint-groups/{groupId}
+GET		/endpoint-groups/{groupId}/endpoints
+POST	/endpoint-groups/{groupId}/endpoints
+DELETE	/endpoint-groups/{groupId}/endpoints
+*/
+
+// GetEndpointGroups returns the endpoint groups of a tenant.
+// Allowed query params: search, searchFields, groupType, ids, page, pageSize, pageTotal
+func (c *Client) GetEndpointGroups(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (EndpointGroups, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/endpoint-groups", queryParams, nil)
+	if err != nil {
+		return EndpointGroups{}, err
+	}
+
+	return UnmarshalEndpointGroups(b)
+}
+
+// SearchEndpointGroups returns the endpoint groups whose name matches search.
+func (c *Client) SearchEndpointGroups(ctx context.Context, tenant TenantsResponseItem, search string) (EndpointGroups, error) {
+	return c.GetEndpointGroups(ctx, tenant, map[string]string{"search": search, "searchFields": "name"})
+}
+
+// GetEndpointGroup returns one endpoint group by id.
+func (c *Client) GetEndpointGroup(ctx context.Context, tenant TenantsResponseItem, groupID string) (EndpointGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return EndpointGroup{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s", groupID), nil, nil)
+	if err != nil {
+		return EndpointGroup{}, err
+	}
+
+	return UnmarshalEndpointGroup(b)
+}
+
+// CreateEndpointGroup creates a computer or server group, optionally with endpoints already in it.
+func (c *Client) CreateEndpointGroup(ctx context.Context, tenant TenantsResponseItem, cgr CreateEndpointGroupRequest) (EndpointGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups
+
+	if cgr.Name == "" {
+		return EndpointGroup{}, ErrMissingInput{Argument: "Name"}
+	}
+	if cgr.Type != ComputerGroup && cgr.Type != ServerGroup {
+		return EndpointGroup{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Type"}, Value: cgr.Type}
+	}
+	if len(cgr.EndpointIDs) > 0 && !areValidUUIDs(cgr.EndpointIDs) {
+		return EndpointGroup{}, ErrEndpointID
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/endpoint/v1/endpoint-groups", nil, cgr)
+	if err != nil {
+		return EndpointGroup{}, err
+	}
+
+	return UnmarshalEndpointGroup(b)
+}
+
+// UpdateEndpointGroup changes the name and/or description of an endpoint group.
+func (c *Client) UpdateEndpointGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, ugr UpdateEndpointGroupRequest) (EndpointGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return EndpointGroup{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+	if err := ugr.validate(); err != nil {
+		return EndpointGroup{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s", groupID), nil, ugr)
+	if err != nil {
+		return EndpointGroup{}, err
+	}
+
+	return UnmarshalEndpointGroup(b)
+}
+
+// DeleteEndpointGroup deletes an endpoint group.  The endpoints in it are not deleted.
+func (c *Client) DeleteEndpointGroup(ctx context.Context, tenant TenantsResponseItem, groupID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s", groupID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// GetEndpointGroupMembers returns the endpoints in an endpoint group.
+// Allowed query params: search, searchFields, page, pageSize, pageTotal
+func (c *Client) GetEndpointGroupMembers(ctx context.Context, tenant TenantsResponseItem, groupID string, queryParams map[string]string) (EndpointGroupMembers, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}/endpoints
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return EndpointGroupMembers{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s/endpoints", groupID), queryParams, nil)
+	if err != nil {
+		return EndpointGroupMembers{}, err
+	}
+
+	return UnmarshalEndpointGroupMembers(b)
+}
+
+// AddEndpointsToGroup adds endpoints to an endpoint group.  The endpoints must be
+// of the same type as the group.
+func (c *Client) AddEndpointsToGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, endpointIDs []string) (EndpointGroupMembershipChange, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}/endpoints
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return EndpointGroupMembershipChange{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+	if !areValidUUIDs(endpointIDs) {
+		return EndpointGroupMembershipChange{}, ErrEndpointID
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s/endpoints", groupID), nil, EndpointIDs{IDs: endpointIDs})
+	if err != nil {
+		return EndpointGroupMembershipChange{}, err
+	}
+
+	return UnmarshalEndpointGroupMembershipChange(b)
+}
+
+// RemoveEndpointsFromGroup removes endpoints from an endpoint group.
+func (c *Client) RemoveEndpointsFromGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, endpointIDs []string) (EndpointGroupMembershipChange, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoint-groups/{groupId}/endpoints?ids=
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return EndpointGroupMembershipChange{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+	if !areValidUUIDs(endpointIDs) {
+		return EndpointGroupMembershipChange{}, ErrEndpointID
+	}
+
+	qp := map[string]string{"ids": strings.Join(endpointIDs, ",")}
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("/endpoint/v1/endpoint-groups/%s/endpoints", groupID), qp, nil)
+	if err != nil {
+		return EndpointGroupMembershipChange{}, err
+	}
+
+	return UnmarshalEndpointGroupMembershipChange(b)
+}
+
+// validate checks that the update changes something.
+func (ugr UpdateEndpointGroupRequest) validate() error {
+	if ugr.Name == "" && ugr.Description == "" {
+		return ErrMissingInput{Argument: "UpdateEndpointGroupRequest"}
+	}
+	return nil
+}
+
+func UnmarshalEndpointGroups(data []byte) (EndpointGroups, error) {
+	var r EndpointGroups
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointGroups{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalEndpointGroup(data []byte) (EndpointGroup, error) {
+	var r EndpointGroup
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointGroup{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalEndpointGroupMembers(data []byte) (EndpointGroupMembers, error) {
+	var r EndpointGroupMembers
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointGroupMembers{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalEndpointGroupMembershipChange(data []byte) (EndpointGroupMembershipChange, error) {
+	var r EndpointGroupMembershipChange
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointGroupMembershipChange{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalDeletedResponse(data []byte) (DeletedResponse, error) {
+	var r DeletedResponse
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type EndpointGroups struct {
+	Items []EndpointGroup `json:"items"`
+	Pages Pages           `json:"pages"`
+}
+
+type EndpointGroup struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Type        EndpointGroupType      `json:"type"`
+	Endpoints   EndpointGroupEndpoints `json:"endpoints"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+type EndpointGroupEndpoints struct {
+	ItemsCount int                   `json:"itemsCount"`
+	Items      []EndpointGroupMember `json:"items"`
+}
+
+type EndpointGroupMember struct {
+	ID       string `json:"id"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+type EndpointGroupMembers struct {
+	Items []EndpointGroupMember `json:"items"`
+	Pages Pages                 `json:"pages"`
+}
+
+type EndpointGroupMembershipChange struct {
+	AddedEndpoints   []EndpointGroupMember `json:"addedEndpoints,omitempty"`
+	RemovedEndpoints []EndpointGroupMember `json:"removedEndpoints,omitempty"`
+}
+
+type CreateEndpointGroupRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Type        EndpointGroupType `json:"type"`
+	EndpointIDs []string          `json:"endpointIds,omitempty"`
+}
+
+type UpdateEndpointGroupRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type EndpointIDs struct {
+	IDs []string `json:"ids"`
+}
+
+type DeletedResponse struct {
+	Deleted bool `json:"deleted"`
+}
+
+type EndpointGroupType string
+
+const (
+	ComputerGroup EndpointGroupType = "computer"
+	ServerGroup   EndpointGroupType = "server"
+)
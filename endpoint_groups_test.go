@@ -0,0 +1,120 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_CreateEndpointGroup(t *testing.T) {
+	a := assert.New(t)
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	tests := []struct {
+		name    string
+		cgr     CreateEndpointGroupRequest
+		want    EndpointGroup
+		wantErr bool
+	}{
+		{
+			name: "one",
+			cgr:  CreateEndpointGroupRequest{Name: "web servers", Type: ServerGroup, EndpointIDs: []string{"bc893b97-86a8-41aa-b65c-910e11505605"}},
+			want: EndpointGroup{
+				ID:   "d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+				Name: "web servers",
+				Type: ServerGroup,
+				Endpoints: EndpointGroupEndpoints{
+					ItemsCount: 1,
+					Items:      []EndpointGroupMember{{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Hostname: "web01"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "missing name",
+			cgr:     CreateEndpointGroupRequest{Type: ServerGroup},
+			want:    EndpointGroup{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			cgr:     CreateEndpointGroupRequest{Name: "web servers", Type: "securityVm"},
+			want:    EndpointGroup{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid endpoint id",
+			cgr:     CreateEndpointGroupRequest{Name: "web servers", Type: ServerGroup, EndpointIDs: []string{"web01"}},
+			want:    EndpointGroup{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				logger: logrus.New(),
+				token:  &oauth2.Token{AccessToken: "access token"},
+				httpClient: httpClientWithRoundTripper(201, `{
+					"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+					"name": "web servers",
+					"type": "server",
+					"endpoints": {
+						"itemsCount": 1,
+						"items": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "hostname": "web01"}]
+					}
+				}`),
+			}
+			got, err := c.CreateEndpointGroup(context.Background(), tenant, tt.cgr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateEndpointGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestClient_UpdateEndpointGroup(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var sent []string
+	hc := httpClientWithRequestRecorder(200, `{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "name": "web servers", "type": "server"}`, func(req *http.Request, body []byte) {
+		a.Equal("PATCH", req.Method)
+		a.Equal("/endpoint/v1/endpoint-groups/d2ba043d-7fcd-4158-a861-1ec2c01f3d14", req.URL.Path)
+		sent = append(sent, string(body))
+	})
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	eg, err := c.UpdateEndpointGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", UpdateEndpointGroupRequest{Description: "public web"})
+	a.NoError(err)
+	a.Equal("web servers", eg.Name)
+
+	_, err = c.UpdateEndpointGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", UpdateEndpointGroupRequest{})
+	a.Error(err)
+	a.Equal([]string{`{"description":"public web"}`}, sent)
+}
+
+func TestClient_RemoveEndpointsFromGroup(t *testing.T) {
+	a := assert.New(t)
+	var gotReq *http.Request
+	c := &Client{
+		logger: logrus.New(),
+		token:  &oauth2.Token{AccessToken: "access token"},
+		httpClient: httpClientWithRequestRecorder(200, `{"removedEndpoints": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605"}]}`, func(req *http.Request, body []byte) {
+			gotReq = req
+		}),
+	}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	got, err := c.RemoveEndpointsFromGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+		[]string{"bc893b97-86a8-41aa-b65c-910e11505605", "03b43abe-4f41-4734-b6d6-70b2fbdc2504"})
+	a.NoError(err)
+	a.Equal("DELETE", gotReq.Method)
+	a.Equal("/endpoint/v1/endpoint-groups/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/endpoints", gotReq.URL.Path)
+	a.Equal("bc893b97-86a8-41aa-b65c-910e11505605,03b43abe-4f41-4734-b6d6-70b2fbdc2504", gotReq.URL.Query().Get("ids"))
+	a.Equal(EndpointGroupMembershipChange{RemovedEndpoints: []EndpointGroupMember{{ID: "bc893b97-86a8-41aa-b65c-910e11505605"}}}, got)
+}
@@ -0,0 +1,158 @@
+package sophoscentral
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+/*
+
+Installer downloads for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/downloads
+*/
+
+// GetInstallers returns the installer download links of a tenant for every platform and
+// product the tenant is licensed for.
+func (c *Client) GetInstallers(ctx context.Context, tenant TenantsResponseItem) (Installers, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/downloads
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/downloads", nil, nil)
+	if err != nil {
+		return Installers{}, err
+	}
+
+	return UnmarshalInstallers(b)
+}
+
+// For returns the installers for platform that include product.
+func (i Installers) For(platform Platform, product Code) []Installer {
+	var found []Installer
+	for _, inst := range i.Items {
+		if inst.Platform == platform && inst.Includes(product) {
+			found = append(found, inst)
+		}
+	}
+	return found
+}
+
+// Includes reports whether the installer installs product.
+func (inst Installer) Includes(product Code) bool {
+	for _, p := range inst.ProductsIncluded {
+		if p == product {
+			return true
+		}
+	}
+	return false
+}
+
+type downloadOptions struct {
+	sha256       string
+	skipChecksum bool
+}
+
+// WithChecksum makes DownloadInstaller verify the download against a hex encoded SHA256.
+func WithChecksum(sha256 string) func(*downloadOptions) {
+	return func(o *downloadOptions) {
+		o.sha256 = strings.ToLower(sha256)
+	}
+}
+
+// WithoutChecksum lets DownloadInstaller download an installer that has no checksum to
+// verify against.
+func WithoutChecksum() func(*downloadOptions) {
+	return func(o *downloadOptions) {
+		o.skipChecksum = true
+	}
+}
+
+// DownloadInstaller streams an installer to w.  The download is verified against the
+// checksum given with WithChecksum, or the one Central published for the installer.  With
+// neither it fails with ErrMissingInput unless WithoutChecksum is given.  Verification can
+// only happen once everything has been written, so on ErrChecksumMismatch whatever was
+// written to w must be thrown away.
+func (c *Client) DownloadInstaller(ctx context.Context, inst Installer, w io.Writer, options ...func(*downloadOptions)) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if inst.DownloadURL == "" {
+		return ErrMissingInput{Argument: "DownloadURL"}
+	}
+
+	opts := downloadOptions{sha256: strings.ToLower(inst.SHA256)}
+	for _, option := range options {
+		option(&opts)
+	}
+	switch {
+	case opts.sha256 != "":
+		if err := ValidateSHA256(opts.sha256); err != nil {
+			return err
+		}
+	case !opts.skipChecksum:
+		return ErrMissingInput{Argument: "SHA256"}
+	}
+
+	// download links are pre-signed so no auth header is sent with them
+	req, err := http.NewRequestWithContext(ctx, "GET", inst.DownloadURL, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToCreateRequest, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrHttpDo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return fmt.Errorf("%s: %w", Err400Returned, errors.New(resp.Status))
+	}
+	if resp.StatusCode >= 500 && resp.StatusCode < 600 {
+		return fmt.Errorf("%s: %w", Err500Returned, errors.New(resp.Status))
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return fmt.Errorf("%s: %w", ErrReadBody, err)
+	}
+
+	if opts.sha256 != "" {
+		if got := hex.EncodeToString(h.Sum(nil)); got != opts.sha256 {
+			return fmt.Errorf("%w: expected %s got %s", ErrChecksumMismatch, opts.sha256, got)
+		}
+	}
+
+	return nil
+}
+
+func UnmarshalInstallers(data []byte) (Installers, error) {
+	var r Installers
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Installers{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type Installers struct {
+	Items []Installer `json:"installers"`
+}
+
+type Installer struct {
+	ProductName      string   `json:"productName"`
+	Platform         Platform `json:"platform"`
+	Type             TypeEP   `json:"type"`
+	ProductsIncluded []Code   `json:"productsIncluded"`
+	DownloadURL      string   `json:"downloadUrl"`
+	SupportedOS      []string `json:"supportedOS,omitempty"`
+	SHA256           string   `json:"sha256,omitempty"`
+}
@@ -0,0 +1,60 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_DownloadInstaller(t *testing.T) {
+	a := assert.New(t)
+
+	// sha256 of "installer bytes"
+	const sum = "e34210a6de4f653edf588301431c3d69a633638cbf587345cc50a7fed9f38f4c"
+	c := &Client{httpClient: httpClientWithRoundTripper(200, "installer bytes")}
+	inst := Installer{Platform: Linux, DownloadURL: "https://downloads.example.com/SophosSetup.sh"}
+
+	var buf bytes.Buffer
+	a.Error(c.DownloadInstaller(context.Background(), inst, &buf))
+	a.Zero(buf.Len())
+
+	a.NoError(c.DownloadInstaller(context.Background(), inst, &buf, WithoutChecksum()))
+	a.Equal("installer bytes", buf.String())
+
+	buf.Reset()
+	a.NoError(c.DownloadInstaller(context.Background(), inst, &buf, WithChecksum(sum)))
+
+	buf.Reset()
+	inst.SHA256 = "E34210A6DE4F653EDF588301431C3D69A633638CBF587345CC50A7FED9F38F4C"
+	a.NoError(c.DownloadInstaller(context.Background(), inst, &buf))
+	inst.SHA256 = ""
+
+	buf.Reset()
+	err := c.DownloadInstaller(context.Background(), inst, &buf, WithChecksum("F0F3B5B0F1E3D6D5C5B6AE6A6CC0D8F9BB0D0D42F98C8DF1E8B9E7A3B4DD0D4C"))
+	a.True(errors.Is(err, ErrChecksumMismatch))
+
+	a.Error(c.DownloadInstaller(context.Background(), inst, &buf, WithChecksum("not a checksum")))
+
+	c = &Client{httpClient: httpClientWithRoundTripper(403, "")}
+	a.Error(c.DownloadInstaller(context.Background(), inst, &buf, WithoutChecksum()))
+}
+
+func TestInstallers_For(t *testing.T) {
+	a := assert.New(t)
+
+	installers, err := UnmarshalInstallers([]byte(`{"installers": [
+		{"productName": "Sophos Endpoint", "platform": "windows", "type": "computer", "productsIncluded": ["coreAgent", "interceptX"], "downloadUrl": "https://downloads.example.com/win"},
+		{"productName": "Sophos Server", "platform": "windows", "type": "server", "productsIncluded": ["coreAgent", "interceptX", "mtr"], "downloadUrl": "https://downloads.example.com/winsrv"},
+		{"productName": "Sophos Linux", "platform": "linux", "type": "server", "productsIncluded": ["coreAgent"], "downloadUrl": "https://downloads.example.com/linux"}
+	]}`))
+	a.NoError(err)
+
+	a.Len(installers.For(Windows, InterceptX), 2)
+	a.Len(installers.For(Windows, MTR), 1)
+	a.Equal(ServerEP, installers.For(Windows, MTR)[0].Type)
+	a.Len(installers.For(Linux, InterceptX), 0)
+	a.Len(installers.For(MacOS, CoreAgent), 0)
+}
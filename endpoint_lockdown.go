@@ -0,0 +1,183 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Server Lockdown for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/endpoints/{endpointId}/lockdown
+POST	/endpoints/{endpointId}/lockdown
+GET		/endpoints/{endpointId}/lockdown/rules
+*/
+
+// GetLockdown returns the lockdown state of a server.
+func (c *Client) GetLockdown(ctx context.Context, tenant TenantsResponseItem, endpointID string) (Lockdown, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/lockdown
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return Lockdown{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/endpoints/%s/lockdown", endpointID), nil, nil)
+	if err != nil {
+		return Lockdown{}, err
+	}
+
+	return UnmarshalLockdown(b)
+}
+
+// LockServer locks down a server so that only the applications on its allow list can run.
+// The server builds its allow list first, so the returned status is usually CreatingWhiteList
+// rather than Locked.
+func (c *Client) LockServer(ctx context.Context, tenant TenantsResponseItem, endpointID string) (Lockdown, error) {
+	return c.setLockdown(ctx, tenant, endpointID, LockdownRequest{Enabled: true})
+}
+
+// UnlockServer removes lockdown from a server.
+func (c *Client) UnlockServer(ctx context.Context, tenant TenantsResponseItem, endpointID string) (Lockdown, error) {
+	return c.setLockdown(ctx, tenant, endpointID, LockdownRequest{Enabled: false})
+}
+
+func (c *Client) setLockdown(ctx context.Context, tenant TenantsResponseItem, endpointID string, lr LockdownRequest) (Lockdown, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/lockdown
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return Lockdown{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoints/%s/lockdown", endpointID), nil, lr)
+	if err != nil {
+		return Lockdown{}, err
+	}
+
+	return UnmarshalLockdown(b)
+}
+
+// GetLockdownRules returns the allow list rules of a locked down server.
+func (c *Client) GetLockdownRules(ctx context.Context, tenant TenantsResponseItem, endpointID string) (LockdownRules, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/lockdown/rules
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return LockdownRules{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	var all LockdownRules
+	err := c.tenantPages(ctx, tenant, fmt.Sprintf("/endpoint/v1/endpoints/%s/lockdown/rules", endpointID), nil, func(b []byte) (Pages, error) {
+		lr, err := UnmarshalLockdownRules(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, lr.Items...)
+		all.Pages = lr.Pages
+		return lr.Pages, nil
+	})
+	if err != nil {
+		return LockdownRules{}, err
+	}
+
+	return all, nil
+}
+
+// GetLockdownReport returns the servers of a tenant grouped by lockdown status.
+func (c *Client) GetLockdownReport(ctx context.Context, tenant TenantsResponseItem) (LockdownReport, error) {
+
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints
+
+	var all Endpoints
+	err := c.tenantPages(ctx, tenant, "/endpoint/v1/endpoints", map[string]string{"type": string(ServerEP), "view": "full"}, func(b []byte) (Pages, error) {
+		eps, err := UnmarshalEndpoints(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Item = append(all.Item, eps.Item...)
+		all.Pages = eps.Pages
+		return eps.Pages, nil
+	})
+	if err != nil {
+		return LockdownReport{}, err
+	}
+
+	return SummariseLockdown(all), nil
+}
+
+// SummariseLockdown groups the servers in eps by lockdown status.  Servers that do not
+// report a lockdown status are kept in NotReported.
+func SummariseLockdown(eps Endpoints) LockdownReport {
+
+	lr := LockdownReport{ByStatus: map[LockdownStatus][]EndpointItem{}}
+	for _, ep := range eps.Item {
+		if ep.Type != ServerEP && !ep.OS.IsServer {
+			continue
+		}
+		if ep.Lockdown == nil || ep.Lockdown.Status == "" {
+			lr.NotReported = append(lr.NotReported, ep)
+			continue
+		}
+		lr.ByStatus[ep.Lockdown.Status] = append(lr.ByStatus[ep.Lockdown.Status], ep)
+	}
+
+	return lr
+}
+
+// Count returns the number of servers with status.
+func (lr LockdownReport) Count(status LockdownStatus) int {
+	return len(lr.ByStatus[status])
+}
+
+func UnmarshalLockdown(data []byte) (Lockdown, error) {
+	var r Lockdown
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Lockdown{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalLockdownRules(data []byte) (LockdownRules, error) {
+	var r LockdownRules
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LockdownRules{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type LockdownRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type LockdownRules struct {
+	Items []LockdownRule `json:"items"`
+	Pages Pages          `json:"pages"`
+}
+
+type LockdownRule struct {
+	ID        string           `json:"id"`
+	Type      LockdownRuleType `json:"type"`
+	Value     string           `json:"value"`
+	Comment   string           `json:"comment,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+type LockdownRuleType string
+
+const (
+	FileRule        LockdownRuleType = "file"
+	FolderRule      LockdownRuleType = "folder"
+	CertificateRule LockdownRuleType = "certificate"
+	ProcessRule     LockdownRuleType = "process"
+)
+
+type LockdownReport struct {
+	ByStatus    map[LockdownStatus][]EndpointItem
+	NotReported []EndpointItem
+}
@@ -0,0 +1,83 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestSummariseLockdown(t *testing.T) {
+	a := assert.New(t)
+
+	eps := Endpoints{Item: []EndpointItem{
+		{ID: "s1", Type: ServerEP, Lockdown: &Lockdown{Status: Locked}},
+		{ID: "s2", Type: ServerEP, Lockdown: &Lockdown{Status: CreatingWhiteList}},
+		{ID: "s3", Type: ServerEP, Lockdown: &Lockdown{Status: Locked}},
+		{ID: "s4", Type: ServerEP},
+		{ID: "s5", Type: ComputerEP, OS: OS{IsServer: true}, Lockdown: &Lockdown{}},
+		{ID: "c1", Type: ComputerEP, Lockdown: &Lockdown{Status: Locked}},
+	}}
+
+	lr := SummariseLockdown(eps)
+	a.Equal(2, lr.Count(Locked))
+	a.Equal(1, lr.Count(CreatingWhiteList))
+	a.Zero(lr.Count(Uninstalled))
+	a.Equal("s3", lr.ByStatus[Locked][1].ID)
+	a.Len(lr.NotReported, 2)
+	a.Equal("s5", lr.NotReported[1].ID)
+}
+
+func TestClient_GetLockdownReport(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var keys []string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/endpoint/v1/endpoints", req.URL.Path)
+			a.Equal("server", req.URL.Query().Get("type"))
+			key := req.URL.Query().Get("pageFromKey")
+			keys = append(keys, key)
+			body := `{"items": [{"id": "s1", "type": "server", "lockdown": {"status": "locked"}}], "pages": {"nextKey": "k2"}}`
+			if key == "k2" {
+				body = `{"items": [{"id": "s2", "type": "server", "lockdown": {"status": "locked"}}], "pages": {}}`
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	lr, err := c.GetLockdownReport(context.Background(), tenant)
+	a.NoError(err)
+	a.Equal([]string{"", "k2"}, keys)
+	a.Equal(2, lr.Count(Locked))
+}
+
+func TestClient_LockServer(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var sent []string
+	hc := httpClientWithRequestRecorder(200, `{"status": "creatingWhiteList"}`, func(req *http.Request, body []byte) {
+		a.Equal("POST", req.Method)
+		a.Equal("/endpoint/v1/endpoints/bc893b97-86a8-41aa-b65c-910e11505605/lockdown", req.URL.Path)
+		sent = append(sent, string(body))
+	})
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	ld, err := c.LockServer(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605")
+	a.NoError(err)
+	a.Equal(CreatingWhiteList, ld.Status)
+	_, err = c.UnlockServer(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605")
+	a.NoError(err)
+	_, err = c.LockServer(context.Background(), tenant, "not an endpoint")
+	a.Error(err)
+
+	a.Equal([]string{`{"enabled":true}`, `{"enabled":false}`}, sent)
+}
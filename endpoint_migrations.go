@@ -0,0 +1,269 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Endpoint migrations for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+A migration job is created on the receiving tenant, which returns a token.  The job is
+then started from the sending tenant with that token.
+
+GET		/migrations
+POST	/migrations
+GET		/migrations/{migrationJobId}
+PUT		/migrations/{migrationJobId}
+GET		/migrations/{migrationJobId}/endpoints
+*/
+
+// DefaultMigrationPollInterval is how often MigrateEndpoints checks migration status
+// when no interval is given.
+const DefaultMigrationPollInterval = 30 * time.Second
+
+// CreateMigrationJob creates a migration job on the receiving tenant, to take the given
+// endpoints from the sending tenant fromTenantID.  The returned token is needed to start it.
+func (c *Client) CreateMigrationJob(ctx context.Context, receiving TenantsResponseItem, fromTenantID string, endpointIDs []string) (MigrationJob, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/migrations
+
+	if _, err := uuid.Parse(fromTenantID); err != nil {
+		return MigrationJob{}, fmt.Errorf("%s: %w", ErrInvalidTenantID, err)
+	}
+	if !areValidUUIDs(endpointIDs) {
+		return MigrationJob{}, ErrEndpointID
+	}
+
+	b, err := c.tenantRequest(ctx, receiving, "POST", "/endpoint/v1/migrations", nil, CreateMigrationJobRequest{FromTenant: fromTenantID, Endpoints: endpointIDs})
+	if err != nil {
+		return MigrationJob{}, err
+	}
+
+	return UnmarshalMigrationJob(b)
+}
+
+// StartMigrationJob starts a migration job from the sending tenant, using the token
+// returned when the job was created on the receiving tenant.
+func (c *Client) StartMigrationJob(ctx context.Context, sending TenantsResponseItem, migrationJobID, token string, endpointIDs []string) (MigrationJob, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/migrations/{migrationJobId}
+
+	if _, err := uuid.Parse(migrationJobID); err != nil {
+		return MigrationJob{}, fmt.Errorf("%s: %w", ErrMigrationID, err)
+	}
+	if token == "" {
+		return MigrationJob{}, ErrMissingInput{Argument: "token"}
+	}
+	if !areValidUUIDs(endpointIDs) {
+		return MigrationJob{}, ErrEndpointID
+	}
+
+	b, err := c.tenantRequest(ctx, sending, "PUT", fmt.Sprintf("/endpoint/v1/migrations/%s", migrationJobID), nil, StartMigrationJobRequest{Token: token, Endpoints: endpointIDs})
+	if err != nil {
+		return MigrationJob{}, err
+	}
+
+	return UnmarshalMigrationJob(b)
+}
+
+// GetMigrationJobs returns the migration jobs of a tenant.
+// Allowed query params: mode (sending or receiving), pageFromKey, pageSize, pageTotal
+func (c *Client) GetMigrationJobs(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (MigrationJobs, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/migrations
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/migrations", queryParams, nil)
+	if err != nil {
+		return MigrationJobs{}, err
+	}
+
+	return UnmarshalMigrationJobs(b)
+}
+
+// GetMigrationJob returns one migration job by id.
+func (c *Client) GetMigrationJob(ctx context.Context, tenant TenantsResponseItem, migrationJobID string) (MigrationJob, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/migrations/{migrationJobId}
+
+	if _, err := uuid.Parse(migrationJobID); err != nil {
+		return MigrationJob{}, fmt.Errorf("%s: %w", ErrMigrationID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/migrations/%s", migrationJobID), nil, nil)
+	if err != nil {
+		return MigrationJob{}, err
+	}
+
+	return UnmarshalMigrationJob(b)
+}
+
+// GetMigrationEndpoints returns the migration status of each endpoint in a migration job.
+func (c *Client) GetMigrationEndpoints(ctx context.Context, tenant TenantsResponseItem, migrationJobID string) (MigrationEndpoints, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/migrations/{migrationJobId}/endpoints
+
+	if _, err := uuid.Parse(migrationJobID); err != nil {
+		return MigrationEndpoints{}, fmt.Errorf("%s: %w", ErrMigrationID, err)
+	}
+
+	var all MigrationEndpoints
+	err := c.tenantPages(ctx, tenant, fmt.Sprintf("/endpoint/v1/migrations/%s/endpoints", migrationJobID), nil, func(b []byte) (Pages, error) {
+		me, err := UnmarshalMigrationEndpoints(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, me.Items...)
+		all.Pages = me.Pages
+		return me.Pages, nil
+	})
+	if err != nil {
+		return MigrationEndpoints{}, err
+	}
+
+	return all, nil
+}
+
+// MigrateEndpoints moves endpoints from one tenant to another and waits for them to finish.
+// It creates the job on the receiving tenant, starts it from the sending tenant, then polls
+// the receiving tenant every pollInterval until every endpoint has succeeded or failed.
+// Both tenants must be visible to the client's credentials, so this needs partner credentials.
+// The last statuses seen are returned along with any error, including when ctx is done.
+func (c *Client) MigrateEndpoints(ctx context.Context, from, to TenantsResponseItem, endpointIDs []string, pollInterval time.Duration) (MigrationEndpoints, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultMigrationPollInterval
+	}
+
+	job, err := c.CreateMigrationJob(ctx, to, from.ID, endpointIDs)
+	if err != nil {
+		return MigrationEndpoints{}, fmt.Errorf("failed to create migration job on receiving tenant: %w", err)
+	}
+
+	if _, err := c.StartMigrationJob(ctx, from, job.ID, job.Token, endpointIDs); err != nil {
+		return MigrationEndpoints{}, fmt.Errorf("failed to start migration job %s on sending tenant: %w", job.ID, err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		me, err := c.GetMigrationEndpoints(ctx, to, job.ID)
+		if err != nil {
+			return me, fmt.Errorf("failed to get status of migration job %s: %w", job.ID, err)
+		}
+		if me.Done(endpointIDs) {
+			return me, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return me, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Done reports whether every one of endpointIDs has finished migrating, successfully or not.
+// An endpoint with no status yet, or a status other than succeeded or failed, is not done.
+func (me MigrationEndpoints) Done(endpointIDs []string) bool {
+	finished := make(map[string]bool, len(me.Items))
+	for _, ep := range me.Items {
+		finished[ep.ID] = ep.Status.finished()
+	}
+	for _, id := range endpointIDs {
+		if !finished[id] {
+			return false
+		}
+	}
+	return len(endpointIDs) > 0
+}
+
+func (s MigrationStatus) finished() bool {
+	return s == MigrationSucceeded || s == MigrationFailed
+}
+
+// Failed returns the endpoints that failed to migrate.
+func (me MigrationEndpoints) Failed() []MigrationEndpoint {
+	var failed []MigrationEndpoint
+	for _, ep := range me.Items {
+		if ep.Status == MigrationFailed {
+			failed = append(failed, ep)
+		}
+	}
+	return failed
+}
+
+func UnmarshalMigrationJob(data []byte) (MigrationJob, error) {
+	var r MigrationJob
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return MigrationJob{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalMigrationJobs(data []byte) (MigrationJobs, error) {
+	var r MigrationJobs
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return MigrationJobs{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalMigrationEndpoints(data []byte) (MigrationEndpoints, error) {
+	var r MigrationEndpoints
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return MigrationEndpoints{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type CreateMigrationJobRequest struct {
+	FromTenant string   `json:"fromTenant"`
+	Endpoints  []string `json:"endpoints"`
+}
+
+type StartMigrationJobRequest struct {
+	Token     string   `json:"token"`
+	Endpoints []string `json:"endpoints"`
+}
+
+type MigrationJobs struct {
+	Items []MigrationJob `json:"items"`
+	Pages Pages          `json:"pages"`
+}
+
+type MigrationJob struct {
+	ID         string    `json:"id"`
+	Token      string    `json:"token,omitempty"`
+	FromTenant *TenantEP `json:"fromTenant,omitempty"`
+	ToTenant   *TenantEP `json:"toTenant,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type MigrationEndpoints struct {
+	Items []MigrationEndpoint `json:"items"`
+	Pages Pages               `json:"pages"`
+}
+
+type MigrationEndpoint struct {
+	ID        string          `json:"id"`
+	Status    MigrationStatus `json:"status"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+type MigrationStatus string
+
+const (
+	MigrationPending   MigrationStatus = "pending"
+	MigrationSucceeded MigrationStatus = "succeeded"
+	MigrationFailed    MigrationStatus = "failed"
+)
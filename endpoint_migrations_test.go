@@ -0,0 +1,74 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_MigrateEndpoints(t *testing.T) {
+	a := assert.New(t)
+
+	from := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	to := TenantsResponseItem{ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504", ApiHost: "https://api-eu01.central.sophos.com"}
+	endpointIDs := []string{"bc893b97-86a8-41aa-b65c-910e11505605"}
+
+	var calls []string
+	polls := 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			calls = append(calls, req.Method+" "+req.Header.Get("X-Tenant-ID")+" "+req.URL.Path)
+			body := `{}`
+			switch {
+			case req.Method == "POST":
+				body = `{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "token": "migration token"}`
+			case req.Method == "PUT":
+				body = `{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14"}`
+			case strings.HasSuffix(req.URL.Path, "/endpoints"):
+				// the job lists no endpoints at first, then one still queued, then the result
+				polls++
+				switch polls {
+				case 1:
+					body = `{"items": []}`
+				case 2:
+					body = `{"items": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "status": "queued"}]}`
+				default:
+					body = `{"items": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "status": "succeeded"}]}`
+				}
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	got, err := c.MigrateEndpoints(context.Background(), from, to, endpointIDs, time.Millisecond)
+	a.NoError(err)
+	a.True(got.Done(endpointIDs))
+	a.Empty(got.Failed())
+	a.Equal([]string{
+		"POST " + to.ID + " /endpoint/v1/migrations",
+		"PUT " + from.ID + " /endpoint/v1/migrations/d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+		"GET " + to.ID + " /endpoint/v1/migrations/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/endpoints",
+		"GET " + to.ID + " /endpoint/v1/migrations/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/endpoints",
+		"GET " + to.ID + " /endpoint/v1/migrations/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/endpoints",
+	}, calls)
+}
+
+func TestMigrationEndpoints_Done(t *testing.T) {
+	a := assert.New(t)
+
+	ids := []string{"bc893b97-86a8-41aa-b65c-910e11505605", "d2ba043d-7fcd-4158-a861-1ec2c01f3d14"}
+	a.False(MigrationEndpoints{}.Done(ids))
+	a.False(MigrationEndpoints{}.Done(nil))
+	a.False(MigrationEndpoints{Items: []MigrationEndpoint{{ID: ids[0], Status: MigrationSucceeded}}}.Done(ids))
+	a.False(MigrationEndpoints{Items: []MigrationEndpoint{{ID: ids[0], Status: MigrationSucceeded}, {ID: ids[1], Status: "unknown"}}}.Done(ids))
+	a.True(MigrationEndpoints{Items: []MigrationEndpoint{{ID: ids[0], Status: MigrationSucceeded}, {ID: ids[1], Status: MigrationFailed}}}.Done(ids))
+}
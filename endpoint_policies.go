@@ -0,0 +1,314 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Policies for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/policies
+POST	/policies
+GET		/policies/{policyId}
+PATCH	/policies/{policyId}
+DELETE	/policies/{policyId}
+POST	/policies/{policyId}/clone
+*/
+
+// GetPolicies returns the policies of a tenant.
+// Allowed query params: policyType, page, pageSize, pageTotal
+func (c *Client) GetPolicies(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (EndpointPolicies, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/policies", queryParams, nil)
+	if err != nil {
+		return EndpointPolicies{}, err
+	}
+
+	return UnmarshalEndpointPolicies(b)
+}
+
+// GetPoliciesByType returns the policies of a tenant that are one of the given types.
+func (c *Client) GetPoliciesByType(ctx context.Context, tenant TenantsResponseItem, policyTypes ...PolicyType) (EndpointPolicies, error) {
+
+	var types []string
+	for _, pt := range policyTypes {
+		types = append(types, string(pt))
+	}
+
+	var qp map[string]string
+	if len(types) > 0 {
+		qp = map[string]string{"policyType": strings.Join(types, ",")}
+	}
+
+	return c.GetPolicies(ctx, tenant, qp)
+}
+
+// GetPolicy returns one policy by id.
+func (c *Client) GetPolicy(ctx context.Context, tenant TenantsResponseItem, policyID string) (EndpointPolicy, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies/{policyId}
+
+	if _, err := uuid.Parse(policyID); err != nil {
+		return EndpointPolicy{}, fmt.Errorf("%s: %w", ErrPolicyID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/policies/%s", policyID), nil, nil)
+	if err != nil {
+		return EndpointPolicy{}, err
+	}
+
+	return UnmarshalEndpointPolicy(b)
+}
+
+// CreatePolicy creates a policy.  Settings that are not given take the recommended values.
+func (c *Client) CreatePolicy(ctx context.Context, tenant TenantsResponseItem, cpr CreatePolicyRequest) (EndpointPolicy, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies
+
+	if cpr.Name == "" {
+		return EndpointPolicy{}, ErrMissingInput{Argument: "Name"}
+	}
+	if cpr.Type == "" {
+		return EndpointPolicy{}, ErrMissingInput{Argument: "Type"}
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/endpoint/v1/policies", nil, cpr)
+	if err != nil {
+		return EndpointPolicy{}, err
+	}
+
+	return UnmarshalEndpointPolicy(b)
+}
+
+// UpdatePolicy changes a policy.  Only the fields set in upr are changed, and only the
+// settings present in upr.Settings are replaced.
+func (c *Client) UpdatePolicy(ctx context.Context, tenant TenantsResponseItem, policyID string, upr UpdatePolicyRequest) (EndpointPolicy, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies/{policyId}
+
+	if _, err := uuid.Parse(policyID); err != nil {
+		return EndpointPolicy{}, fmt.Errorf("%s: %w", ErrPolicyID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/endpoint/v1/policies/%s", policyID), nil, upr)
+	if err != nil {
+		return EndpointPolicy{}, err
+	}
+
+	return UnmarshalEndpointPolicy(b)
+}
+
+// DeletePolicy deletes a policy.  Base policies cannot be deleted.
+func (c *Client) DeletePolicy(ctx context.Context, tenant TenantsResponseItem, policyID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies/{policyId}
+
+	if _, err := uuid.Parse(policyID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrPolicyID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("/endpoint/v1/policies/%s", policyID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// ClonePolicy copies a policy and its settings to a new policy called name.
+func (c *Client) ClonePolicy(ctx context.Context, tenant TenantsResponseItem, policyID string, name string) (EndpointPolicy, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/policies/{policyId}/clone
+
+	if _, err := uuid.Parse(policyID); err != nil {
+		return EndpointPolicy{}, fmt.Errorf("%s: %w", ErrPolicyID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/policies/%s/clone", policyID), nil, ClonePolicyRequest{Name: name})
+	if err != nil {
+		return EndpointPolicy{}, err
+	}
+
+	return UnmarshalEndpointPolicy(b)
+}
+
+// ReorderPolicies sets the priority of the given policies to their position in policyIDs,
+// the first being the highest priority.  The policies should all be of the same type and
+// not include the base policy, which always has the lowest priority.
+func (c *Client) ReorderPolicies(ctx context.Context, tenant TenantsResponseItem, policyIDs []string) ([]EndpointPolicy, error) {
+
+	if !areValidUUIDs(policyIDs) {
+		return nil, ErrPolicyID
+	}
+
+	var reordered []EndpointPolicy
+	for i, id := range policyIDs {
+		priority := i + 1
+		p, err := c.UpdatePolicy(ctx, tenant, id, UpdatePolicyRequest{Priority: &priority})
+		if err != nil {
+			return reordered, fmt.Errorf("failed to set priority of policy %s: %w", id, err)
+		}
+		reordered = append(reordered, p)
+	}
+
+	return reordered, nil
+}
+
+func UnmarshalEndpointPolicies(data []byte) (EndpointPolicies, error) {
+	var r EndpointPolicies
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointPolicies{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalEndpointPolicy(data []byte) (EndpointPolicy, error) {
+	var r EndpointPolicy
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return EndpointPolicy{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type EndpointPolicies struct {
+	Items []EndpointPolicy `json:"items"`
+	Pages Pages            `json:"pages"`
+}
+
+type EndpointPolicy struct {
+	ID                      string          `json:"id"`
+	Name                    string          `json:"name"`
+	Type                    PolicyType      `json:"type"`
+	Enabled                 bool            `json:"enabled"`
+	Priority                int             `json:"priority"`
+	Settings                PolicySettings  `json:"settings,omitempty"`
+	AppliesTo               PolicyAppliesTo `json:"appliesTo"`
+	LockedByManagingAccount bool            `json:"lockedByManagingAccount,omitempty"`
+	CreatedAt               time.Time       `json:"createdAt"`
+	UpdatedAt               time.Time       `json:"updatedAt"`
+}
+
+// IsBase reports whether p is the base policy of its type, which applies to everything
+// no other policy applies to.
+func (p EndpointPolicy) IsBase() bool {
+	return p.Priority == 0
+}
+
+type PolicyAppliesTo struct {
+	Users     []PolicyAssignee `json:"users,omitempty"`
+	Groups    []PolicyAssignee `json:"groups,omitempty"`
+	Endpoints []PolicyAssignee `json:"endpoints,omitempty"`
+}
+
+type PolicyAssignee struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// PolicySettings are keyed by the dotted setting name, for example
+// "endpoint.threat-protection.malware-protection.deep-learning.enabled".
+// Each policy type has its own set of settings so they are kept as a map, with
+// accessors for the value types the settings use.
+type PolicySettings map[string]PolicySetting
+
+type PolicySetting struct {
+	Value       interface{} `json:"value"`
+	Recommended *bool       `json:"recommended,omitempty"`
+}
+
+// Bool returns a boolean setting.  ok is false when the setting is missing or not a boolean.
+func (ps PolicySettings) Bool(key string) (v bool, ok bool) {
+	v, ok = ps[key].Value.(bool)
+	return v, ok
+}
+
+// String returns a string setting.  ok is false when the setting is missing or not a string.
+func (ps PolicySettings) String(key string) (v string, ok bool) {
+	v, ok = ps[key].Value.(string)
+	return v, ok
+}
+
+// Int returns a numeric setting.  ok is false when the setting is missing or not a number.
+func (ps PolicySettings) Int(key string) (int, bool) {
+	switch v := ps[key].Value.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+// Strings returns a list setting.  ok is false when the setting is missing or not a list of strings.
+func (ps PolicySettings) Strings(key string) ([]string, bool) {
+	switch v := ps[key].Value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		ss := make([]string, 0, len(v))
+		for _, i := range v {
+			s, ok := i.(string)
+			if !ok {
+				return nil, false
+			}
+			ss = append(ss, s)
+		}
+		return ss, true
+	}
+	return nil, false
+}
+
+// Set sets the value of a setting, making the map first when it is nil.
+func (ps *PolicySettings) Set(key string, value interface{}) {
+	if *ps == nil {
+		*ps = PolicySettings{}
+	}
+	(*ps)[key] = PolicySetting{Value: value}
+}
+
+type CreatePolicyRequest struct {
+	Name      string           `json:"name"`
+	Type      PolicyType       `json:"type"`
+	Enabled   *bool            `json:"enabled,omitempty"`
+	Priority  *int             `json:"priority,omitempty"`
+	Settings  PolicySettings   `json:"settings,omitempty"`
+	AppliesTo *PolicyAppliesTo `json:"appliesTo,omitempty"`
+}
+
+type UpdatePolicyRequest struct {
+	Name      string           `json:"name,omitempty"`
+	Enabled   *bool            `json:"enabled,omitempty"`
+	Priority  *int             `json:"priority,omitempty"`
+	Settings  PolicySettings   `json:"settings,omitempty"`
+	AppliesTo *PolicyAppliesTo `json:"appliesTo,omitempty"`
+}
+
+type ClonePolicyRequest struct {
+	Name string `json:"name"`
+}
+
+type PolicyType string
+
+const (
+	AgentUpdatingPolicy            PolicyType = "agent-updating"
+	ApplicationControlPolicy       PolicyType = "application-control"
+	DataLossPreventionPolicy       PolicyType = "data-loss-prevention"
+	DeviceEncryptionPolicy         PolicyType = "device-encryption"
+	PeripheralControlPolicy        PolicyType = "peripheral-control"
+	ThreatProtectionPolicy         PolicyType = "threat-protection"
+	WebControlPolicy               PolicyType = "web-control"
+	WindowsFirewallPolicy          PolicyType = "windows-firewall"
+	ServerApplicationControlPolicy PolicyType = "server-application-control"
+	ServerDataLossPreventionPolicy PolicyType = "server-data-loss-prevention"
+	ServerLockdownPolicy           PolicyType = "server-lockdown"
+	ServerPeripheralControlPolicy  PolicyType = "server-peripheral-control"
+	ServerThreatProtectionPolicy   PolicyType = "server-threat-protection"
+	ServerWebControlPolicy         PolicyType = "server-web-control"
+	ServerWindowsFirewallPolicy    PolicyType = "server-windows-firewall"
+)
@@ -0,0 +1,94 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestUnmarshalEndpointPolicy(t *testing.T) {
+	a := assert.New(t)
+
+	p, err := UnmarshalEndpointPolicy([]byte(`{
+		"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14",
+		"name": "Build servers",
+		"type": "server-threat-protection",
+		"enabled": true,
+		"priority": 2,
+		"settings": {
+			"endpoint.threat-protection.malware-protection.deep-learning.enabled": {"value": true, "recommended": true},
+			"endpoint.threat-protection.malware-protection.scheduled-scan.time": {"value": "20:00"},
+			"endpoint.threat-protection.malware-protection.scheduled-scan.days": {"value": ["monday", "friday"]},
+			"endpoint.threat-protection.malware-protection.on-access.timeout": {"value": 30}
+		},
+		"appliesTo": {
+			"groups": [{"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504", "name": "build"}]
+		}
+	}`))
+	a.NoError(err)
+	a.Equal(ServerThreatProtectionPolicy, p.Type)
+	a.False(p.IsBase())
+	a.Equal([]PolicyAssignee{{ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504", Name: "build"}}, p.AppliesTo.Groups)
+
+	b, ok := p.Settings.Bool("endpoint.threat-protection.malware-protection.deep-learning.enabled")
+	a.True(ok)
+	a.True(b)
+
+	s, ok := p.Settings.String("endpoint.threat-protection.malware-protection.scheduled-scan.time")
+	a.True(ok)
+	a.Equal("20:00", s)
+
+	ss, ok := p.Settings.Strings("endpoint.threat-protection.malware-protection.scheduled-scan.days")
+	a.True(ok)
+	a.Equal([]string{"monday", "friday"}, ss)
+
+	i, ok := p.Settings.Int("endpoint.threat-protection.malware-protection.on-access.timeout")
+	a.True(ok)
+	a.Equal(30, i)
+
+	_, ok = p.Settings.Bool("endpoint.threat-protection.malware-protection.scheduled-scan.time")
+	a.False(ok)
+	_, ok = p.Settings.String("not.a.setting")
+	a.False(ok)
+}
+
+func TestPolicySettings_Set(t *testing.T) {
+	a := assert.New(t)
+
+	var req UpdatePolicyRequest
+	req.Settings.Set("endpoint.threat-protection.malware-protection.scheduled-scan.enabled", true)
+	v, ok := req.Settings.Bool("endpoint.threat-protection.malware-protection.scheduled-scan.enabled")
+	a.True(ok)
+	a.True(v)
+
+	req.Settings.Set("endpoint.threat-protection.malware-protection.scheduled-scan.enabled", false)
+	v, _ = req.Settings.Bool("endpoint.threat-protection.malware-protection.scheduled-scan.enabled")
+	a.False(v)
+	a.Len(req.Settings, 1)
+}
+
+func TestClient_ReorderPolicies(t *testing.T) {
+	a := assert.New(t)
+	var bodies []string
+	c := &Client{
+		logger: logrus.New(),
+		token:  &oauth2.Token{AccessToken: "access token"},
+		httpClient: httpClientWithRequestRecorder(200, `{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14"}`, func(req *http.Request, body []byte) {
+			a.Equal("PATCH", req.Method)
+			bodies = append(bodies, string(body))
+		}),
+	}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	got, err := c.ReorderPolicies(context.Background(), tenant, []string{"d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "03b43abe-4f41-4734-b6d6-70b2fbdc2504"})
+	a.NoError(err)
+	a.Len(got, 2)
+	a.Equal([]string{`{"priority":1}`, `{"priority":2}`}, bodies)
+
+	_, err = c.ReorderPolicies(context.Background(), tenant, []string{"base"})
+	a.Error(err)
+}
@@ -0,0 +1,175 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+On demand actions for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+POST	/endpoints/{endpointId}/scans
+POST	/endpoints/{endpointId}/update-checks
+*/
+
+// DefaultEndpointConcurrency is the number of endpoints actioned at once by the bulk
+// calls when no concurrency is given.
+const DefaultEndpointConcurrency = 5
+
+// ScanEndpoint starts an on demand scan on an endpoint.
+func (c *Client) ScanEndpoint(ctx context.Context, tenant TenantsResponseItem, endpointID string) (ScanResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/scans
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return ScanResponse{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoints/%s/scans", endpointID), nil, struct{}{})
+	if err != nil {
+		return ScanResponse{}, err
+	}
+
+	return UnmarshalScanResponse(b)
+}
+
+// CheckForUpdates makes an endpoint check for and apply updates.
+func (c *Client) CheckForUpdates(ctx context.Context, tenant TenantsResponseItem, endpointID string) (UpdateCheckResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/update-checks
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return UpdateCheckResponse{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoints/%s/update-checks", endpointID), nil, struct{}{})
+	if err != nil {
+		return UpdateCheckResponse{}, err
+	}
+
+	return UnmarshalUpdateCheckResponse(b)
+}
+
+// ScanEndpoints starts an on demand scan on every endpoint in eps, running at most
+// concurrency requests at once.  One result is returned per endpoint in the order of eps.
+func (c *Client) ScanEndpoints(ctx context.Context, tenant TenantsResponseItem, eps Endpoints, concurrency int) []EndpointBulkResult {
+	return forEachEndpoint(ctx, eps, concurrency, func(ctx context.Context, ep EndpointItem) (string, time.Time, error) {
+		sr, err := c.ScanEndpoint(ctx, tenant, ep.ID)
+		return sr.ID, sr.RequestedAt, err
+	})
+}
+
+// CheckForUpdatesOnEndpoints makes every endpoint in eps check for updates, running at
+// most concurrency requests at once.  One result is returned per endpoint in the order of eps.
+func (c *Client) CheckForUpdatesOnEndpoints(ctx context.Context, tenant TenantsResponseItem, eps Endpoints, concurrency int) []EndpointBulkResult {
+	return forEachEndpoint(ctx, eps, concurrency, func(ctx context.Context, ep EndpointItem) (string, time.Time, error) {
+		ucr, err := c.CheckForUpdates(ctx, tenant, ep.ID)
+		return ucr.ID, ucr.RequestedAt, err
+	})
+}
+
+// forEachEndpoint runs action against each endpoint with no more than concurrency
+// actions in flight.  Endpoints not yet started when ctx is done get ctx.Err().
+func forEachEndpoint(ctx context.Context, eps Endpoints, concurrency int, action func(context.Context, EndpointItem) (string, time.Time, error)) []EndpointBulkResult {
+
+	results := make([]EndpointBulkResult, len(eps.Item))
+	for i, ep := range eps.Item {
+		results[i] = EndpointBulkResult{EndpointID: ep.ID, Hostname: ep.Hostname}
+	}
+
+	errs := runForEndpoints(ctx, eps, concurrency, func(ctx context.Context, i int, ep EndpointItem) error {
+		var err error
+		results[i].RequestID, results[i].RequestedAt, err = action(ctx, ep)
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+
+	return results
+}
+
+// runForEndpoints calls fn for each endpoint in eps, with no more than concurrency calls in
+// flight, and returns the error of each call by endpoint index.  Endpoints not yet started
+// when ctx is done are not called and get ctx.Err().
+func runForEndpoints(ctx context.Context, eps Endpoints, concurrency int, fn func(ctx context.Context, i int, ep EndpointItem) error) []error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if concurrency < 1 {
+		concurrency = DefaultEndpointConcurrency
+	}
+
+	errs := make([]error, len(eps.Item))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, ep := range eps.Item {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, ep EndpointItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, i, ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func UnmarshalScanResponse(data []byte) (ScanResponse, error) {
+	var r ScanResponse
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return ScanResponse{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalUpdateCheckResponse(data []byte) (UpdateCheckResponse, error) {
+	var r UpdateCheckResponse
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return UpdateCheckResponse{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type ScanResponse struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+type UpdateCheckResponse struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// EndpointBulkResult is the outcome of a bulk action for a single endpoint.
+// Err is set when the request for that endpoint failed.
+type EndpointBulkResult struct {
+	EndpointID  string
+	Hostname    string
+	RequestID   string
+	RequestedAt time.Time
+	Err         error
+}
@@ -0,0 +1,82 @@
+package sophoscentral
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_ScanEndpoint(t *testing.T) {
+	a := assert.New(t)
+	c := &Client{
+		logger: logrus.New(),
+		token:  &oauth2.Token{AccessToken: "access token"},
+		httpClient: httpClientWithRoundTripper(201, `{
+			"id": "49310a33-4acc-409b-aafb-07b8bc06ef01",
+			"status": "requested",
+			"requestedAt": "2021-05-02T06:00:25.454Z"
+		}`),
+	}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	got, err := c.ScanEndpoint(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605")
+	a.NoError(err)
+	a.Equal(ScanResponse{
+		ID:          "49310a33-4acc-409b-aafb-07b8bc06ef01",
+		Status:      "requested",
+		RequestedAt: mustParseTime("2021-05-02T06:00:25.454Z", time.RFC3339),
+	}, got)
+
+	_, err = c.CheckForUpdates(context.Background(), tenant, "not an id")
+	a.Error(err)
+}
+
+func Test_forEachEndpoint(t *testing.T) {
+	a := assert.New(t)
+
+	var eps Endpoints
+	for i := 0; i < 20; i++ {
+		eps.Item = append(eps.Item, EndpointItem{ID: uuid.New().String(), Hostname: "host"})
+	}
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	results := forEachEndpoint(context.Background(), eps, 3, func(ctx context.Context, ep EndpointItem) (string, time.Time, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return "req-" + ep.ID, time.Time{}, nil
+	})
+
+	a.Len(results, 20)
+	a.LessOrEqual(maxInFlight, 3)
+	for i, r := range results {
+		a.Equal(eps.Item[i].ID, r.EndpointID)
+		a.Equal("req-"+eps.Item[i].ID, r.RequestID)
+		a.NoError(r.Err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = forEachEndpoint(ctx, eps, 3, func(ctx context.Context, ep EndpointItem) (string, time.Time, error) {
+		return "", time.Time{}, nil
+	})
+	for _, r := range results {
+		a.Error(r.Err)
+	}
+}
@@ -0,0 +1,208 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+
+Software packages for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/software/packages/fixed
+GET		/software/packages/recommended
+
+Update management (which package endpoints run and when they update) is set with
+agent-updating policies, see endpoint_policies.go.
+*/
+
+// Setting keys of agent-updating policies read by UpdateManagementSettings.
+const (
+	UpdateSettingReleaseType      = "endpoint.agent-updating.release-type"
+	UpdateSettingFixedPackageID   = "endpoint.agent-updating.fixed-package-id"
+	UpdateSettingScheduledEnabled = "endpoint.agent-updating.scheduled-updates.enabled"
+	UpdateSettingScheduledDay     = "endpoint.agent-updating.scheduled-updates.day"
+	UpdateSettingScheduledTime    = "endpoint.agent-updating.scheduled-updates.time"
+)
+
+// GetFixedSoftwarePackages returns the fixed version packages endpoints can be pinned to.
+// Allowed query params: platform, productIds
+func (c *Client) GetFixedSoftwarePackages(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (SoftwarePackages, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/software/packages/fixed
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/software/packages/fixed", queryParams, nil)
+	if err != nil {
+		return SoftwarePackages{}, err
+	}
+
+	return UnmarshalSoftwarePackages(b)
+}
+
+// GetRecommendedSoftwarePackages returns the packages endpoints on the recommended release run.
+// Allowed query params: platform, productIds
+func (c *Client) GetRecommendedSoftwarePackages(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (SoftwarePackages, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/software/packages/recommended
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/software/packages/recommended", queryParams, nil)
+	if err != nil {
+		return SoftwarePackages{}, err
+	}
+
+	return UnmarshalSoftwarePackages(b)
+}
+
+// GetUpdateManagementPolicies returns the agent-updating policies of a tenant.
+func (c *Client) GetUpdateManagementPolicies(ctx context.Context, tenant TenantsResponseItem) (EndpointPolicies, error) {
+	return c.GetPoliciesByType(ctx, tenant, AgentUpdatingPolicy)
+}
+
+// PinSoftwarePackage pins the endpoints an agent-updating policy applies to to a fixed package.
+func (c *Client) PinSoftwarePackage(ctx context.Context, tenant TenantsResponseItem, policyID string, packageID string) (EndpointPolicy, error) {
+
+	if packageID == "" {
+		return EndpointPolicy{}, ErrMissingInput{Argument: "packageID"}
+	}
+
+	settings := PolicySettings{}
+	settings.Set(UpdateSettingReleaseType, string(FixedRelease))
+	settings.Set(UpdateSettingFixedPackageID, packageID)
+
+	return c.UpdatePolicy(ctx, tenant, policyID, UpdatePolicyRequest{Settings: settings})
+}
+
+// UpdateManagementSettings reads the update settings of an agent-updating policy.
+func (p EndpointPolicy) UpdateManagementSettings() UpdateManagementSettings {
+	var ums UpdateManagementSettings
+
+	rt, _ := p.Settings.String(UpdateSettingReleaseType)
+	ums.ReleaseType = ReleaseType(rt)
+	ums.FixedPackageID, _ = p.Settings.String(UpdateSettingFixedPackageID)
+	ums.ScheduledUpdates, _ = p.Settings.Bool(UpdateSettingScheduledEnabled)
+	ums.ScheduledDay, _ = p.Settings.String(UpdateSettingScheduledDay)
+	ums.ScheduledTime, _ = p.Settings.String(UpdateSettingScheduledTime)
+
+	return ums
+}
+
+// Find returns the package that installs version of product on platform.  Versions match
+// when equal, or when the endpoint's version has the package version as a dotted prefix,
+// so an endpoint on 2022.1.0.78 matches a package at 2022.1.
+func (sp SoftwarePackages) Find(platform Platform, product Code, version string) (SoftwarePackage, bool) {
+	for _, p := range sp.Items {
+		if !p.SupportsPlatform(platform) {
+			continue
+		}
+		for _, pp := range p.Products {
+			if pp.Code == product && versionMatches(version, pp.Version) {
+				return p, true
+			}
+		}
+	}
+	return SoftwarePackage{}, false
+}
+
+// SupportsPlatform reports whether the package can be installed on platform.  Packages that
+// do not list their platforms are taken to support all of them.
+func (p SoftwarePackage) SupportsPlatform(platform Platform) bool {
+	if len(p.Platforms) == 0 {
+		return true
+	}
+	for _, pp := range p.Platforms {
+		if pp == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiresWithin reports whether the package expires within d of now.  Expired packages are included.
+func (p SoftwarePackage) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return p.ExpiresAt != nil && p.ExpiresAt.Before(now.Add(d))
+}
+
+// ExpiringPackages cross references the assigned products of each endpoint with the packages
+// and returns every endpoint product running a package that expires within d of now, soonest first.
+func ExpiringPackages(eps Endpoints, pkgs SoftwarePackages, d time.Duration, now time.Time) []EndpointPackageExpiry {
+
+	var expiring []EndpointPackageExpiry
+	for _, ep := range eps.Item {
+		for _, ap := range ep.AssignedProducts {
+			p, ok := pkgs.Find(ep.OS.Platform, ap.Code, ap.Version)
+			if !ok || !p.ExpiresWithin(d, now) {
+				continue
+			}
+			expiring = append(expiring, EndpointPackageExpiry{Endpoint: ep, Product: ap, Package: p})
+		}
+	}
+
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Package.ExpiresAt.Before(*expiring[j].Package.ExpiresAt)
+	})
+
+	return expiring
+}
+
+func versionMatches(endpointVersion, packageVersion string) bool {
+	if endpointVersion == "" || packageVersion == "" {
+		return false
+	}
+	return endpointVersion == packageVersion || strings.HasPrefix(endpointVersion, packageVersion+".")
+}
+
+func UnmarshalSoftwarePackages(data []byte) (SoftwarePackages, error) {
+	var r SoftwarePackages
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SoftwarePackages{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type SoftwarePackages struct {
+	Items []SoftwarePackage `json:"items"`
+	Pages Pages             `json:"pages"`
+}
+
+type SoftwarePackage struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Version     string                   `json:"version"`
+	ReleaseType ReleaseType              `json:"releaseType,omitempty"`
+	Platforms   []Platform               `json:"platforms,omitempty"`
+	Products    []SoftwarePackageProduct `json:"products"`
+	ReleasedAt  *time.Time               `json:"releasedAt,omitempty"`
+	ExpiresAt   *time.Time               `json:"expiresAt,omitempty"`
+}
+
+type SoftwarePackageProduct struct {
+	Code    Code   `json:"code"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version"`
+}
+
+type ReleaseType string
+
+const (
+	RecommendedRelease ReleaseType = "recommended"
+	FixedRelease       ReleaseType = "fixed"
+)
+
+type UpdateManagementSettings struct {
+	ReleaseType      ReleaseType
+	FixedPackageID   string
+	ScheduledUpdates bool
+	ScheduledDay     string
+	ScheduledTime    string
+}
+
+// EndpointPackageExpiry is an endpoint product running a package that is about to expire.
+type EndpointPackageExpiry struct {
+	Endpoint EndpointItem
+	Product  AssignedProduct
+	Package  SoftwarePackage
+}
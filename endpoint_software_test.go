@@ -0,0 +1,51 @@
+package sophoscentral
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiringPackages(t *testing.T) {
+	a := assert.New(t)
+
+	now := mustParseTime("2021-05-01T00:00:00Z", time.RFC3339)
+	pkgs, err := UnmarshalSoftwarePackages([]byte(`{"items": [
+		{"id": "lts-2021.1", "name": "LTS 2021.1", "releaseType": "fixed", "platforms": ["windows"],
+		 "products": [{"code": "interceptX", "version": "2021.1"}], "expiresAt": "2021-05-20T00:00:00Z"},
+		{"id": "lts-2020.2", "name": "LTS 2020.2", "releaseType": "fixed", "platforms": ["windows"],
+		 "products": [{"code": "interceptX", "version": "2020.2"}], "expiresAt": "2021-05-10T00:00:00Z"},
+		{"id": "lts-2021.2", "name": "LTS 2021.2", "releaseType": "fixed",
+		 "products": [{"code": "interceptX", "version": "2021.2"}], "expiresAt": "2022-05-01T00:00:00Z"}
+	]}`))
+	a.NoError(err)
+
+	eps := Endpoints{Item: []EndpointItem{
+		{ID: "one", OS: OS{Platform: Windows}, AssignedProducts: []AssignedProduct{{Code: InterceptX, Version: "2021.1.0.78"}, {Code: CoreAgent, Version: "2021.1.0.78"}}},
+		{ID: "two", OS: OS{Platform: Windows}, AssignedProducts: []AssignedProduct{{Code: InterceptX, Version: "2020.2.1.12"}}},
+		{ID: "three", OS: OS{Platform: Windows}, AssignedProducts: []AssignedProduct{{Code: InterceptX, Version: "2021.2.0.1"}}},
+		{ID: "four", OS: OS{Platform: Linux}, AssignedProducts: []AssignedProduct{{Code: InterceptX, Version: "2021.1.0.78"}}},
+		{ID: "five", OS: OS{Platform: Windows}, AssignedProducts: []AssignedProduct{{Code: InterceptX, Version: "2021.10.0.1"}}},
+	}}
+
+	got := ExpiringPackages(eps, pkgs, 30*24*time.Hour, now)
+	a.Len(got, 2)
+	a.Equal("two", got[0].Endpoint.ID)
+	a.Equal("lts-2020.2", got[0].Package.ID)
+	a.Equal("one", got[1].Endpoint.ID)
+	a.Equal(InterceptX, got[1].Product.Code)
+
+	a.Empty(ExpiringPackages(eps, pkgs, 24*time.Hour, now))
+}
+
+func TestEndpointPolicy_UpdateManagementSettings(t *testing.T) {
+	a := assert.New(t)
+
+	p := EndpointPolicy{Type: AgentUpdatingPolicy, Settings: PolicySettings{}}
+	p.Settings.Set(UpdateSettingReleaseType, "fixed")
+	p.Settings.Set(UpdateSettingFixedPackageID, "lts-2021.1")
+	p.Settings.Set(UpdateSettingScheduledEnabled, true)
+
+	a.Equal(UpdateManagementSettings{ReleaseType: FixedRelease, FixedPackageID: "lts-2021.1", ScheduledUpdates: true}, p.UpdateManagementSettings())
+}
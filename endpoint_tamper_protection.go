@@ -0,0 +1,126 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Tamper protection for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/endpoints/{endpointId}/tamper-protection
+POST	/endpoints/{endpointId}/tamper-protection
+GET		/settings/tamper-protection
+POST	/settings/tamper-protection
+*/
+
+// GetTamperProtection returns the tamper protection status of an endpoint along with
+// the current and previous tamper protection passwords.
+func (c *Client) GetTamperProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string) (TamperProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/tamper-protection
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return TamperProtection{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/endpoint/v1/endpoints/%s/tamper-protection", endpointID), nil, nil)
+	if err != nil {
+		return TamperProtection{}, err
+	}
+
+	return UnmarshalTamperProtection(b)
+}
+
+// SetTamperProtection turns tamper protection on or off for an endpoint.
+func (c *Client) SetTamperProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string, enabled bool) (TamperProtection, error) {
+	return c.updateTamperProtection(ctx, tenant, endpointID, TamperProtectionUpdate{Enabled: &enabled})
+}
+
+// RegenerateTamperPassword generates a new tamper protection password for an endpoint.
+// The password that was in use is returned in PreviousPasswords.
+func (c *Client) RegenerateTamperPassword(ctx context.Context, tenant TenantsResponseItem, endpointID string) (TamperProtection, error) {
+	return c.updateTamperProtection(ctx, tenant, endpointID, TamperProtectionUpdate{RegeneratePassword: true})
+}
+
+func (c *Client) updateTamperProtection(ctx context.Context, tenant TenantsResponseItem, endpointID string, tpu TamperProtectionUpdate) (TamperProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/endpoints/{endpointId}/tamper-protection
+
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return TamperProtection{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/endpoint/v1/endpoints/%s/tamper-protection", endpointID), nil, tpu)
+	if err != nil {
+		return TamperProtection{}, err
+	}
+
+	return UnmarshalTamperProtection(b)
+}
+
+// GetGlobalTamperProtection returns the tenant wide tamper protection setting.
+func (c *Client) GetGlobalTamperProtection(ctx context.Context, tenant TenantsResponseItem) (GlobalTamperProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/tamper-protection
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/endpoint/v1/settings/tamper-protection", nil, nil)
+	if err != nil {
+		return GlobalTamperProtection{}, err
+	}
+
+	return UnmarshalGlobalTamperProtection(b)
+}
+
+// SetGlobalTamperProtection turns the tenant wide tamper protection setting on or off.
+func (c *Client) SetGlobalTamperProtection(ctx context.Context, tenant TenantsResponseItem, enabled bool) (GlobalTamperProtection, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/tamper-protection
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/endpoint/v1/settings/tamper-protection", nil, GlobalTamperProtection{Enabled: enabled})
+	if err != nil {
+		return GlobalTamperProtection{}, err
+	}
+
+	return UnmarshalGlobalTamperProtection(b)
+}
+
+func UnmarshalTamperProtection(data []byte) (TamperProtection, error) {
+	var r TamperProtection
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return TamperProtection{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalGlobalTamperProtection(data []byte) (GlobalTamperProtection, error) {
+	var r GlobalTamperProtection
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return GlobalTamperProtection{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type TamperProtection struct {
+	Enabled           bool               `json:"enabled"`
+	Password          string             `json:"password,omitempty"`
+	PreviousPasswords []PreviousPassword `json:"previousPasswords,omitempty"`
+}
+
+type PreviousPassword struct {
+	Password      string    `json:"password"`
+	InvalidatedAt time.Time `json:"invalidatedAt"`
+}
+
+type TamperProtectionUpdate struct {
+	Enabled            *bool `json:"enabled,omitempty"`
+	RegeneratePassword bool  `json:"regeneratePassword,omitempty"`
+}
+
+type GlobalTamperProtection struct {
+	Enabled bool `json:"enabled"`
+}
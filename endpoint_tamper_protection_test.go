@@ -0,0 +1,112 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_GetTamperProtection(t *testing.T) {
+	a := assert.New(t)
+	tenant := TenantsResponseItem{
+		ID:      "49310a33-4acc-409b-aafb-07b8bc06ef01",
+		ApiHost: "https://api-us03.central.sophos.com",
+	}
+	tests := []struct {
+		name       string
+		httpClient *http.Client
+		tenant     TenantsResponseItem
+		endpointID string
+		want       TamperProtection
+		wantErr    bool
+	}{
+		{
+			name: "one",
+			httpClient: httpClientWithRoundTripper(200, `{
+				"enabled": true,
+				"password": "current",
+				"previousPasswords": [
+					{"password": "older", "invalidatedAt": "2021-05-02T06:00:25.454Z"}
+				]
+			}`),
+			tenant:     tenant,
+			endpointID: "bc893b97-86a8-41aa-b65c-910e11505605",
+			want: TamperProtection{
+				Enabled:  true,
+				Password: "current",
+				PreviousPasswords: []PreviousPassword{
+					{Password: "older", InvalidatedAt: mustParseTime("2021-05-02T06:00:25.454Z", time.RFC3339)},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:       "invalid endpoint id",
+			httpClient: httpClientWithRoundTripper(200, `{}`),
+			tenant:     tenant,
+			endpointID: "not an id",
+			want:       TamperProtection{},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid tenant id",
+			httpClient: httpClientWithRoundTripper(200, `{}`),
+			tenant:     TenantsResponseItem{ID: "not an id"},
+			endpointID: "bc893b97-86a8-41aa-b65c-910e11505605",
+			want:       TamperProtection{},
+			wantErr:    true,
+		},
+		{
+			name:       "500 error",
+			httpClient: httpClientWithRoundTripper(500, ``),
+			tenant:     tenant,
+			endpointID: "bc893b97-86a8-41aa-b65c-910e11505605",
+			want:       TamperProtection{},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				ctx:        context.Background(),
+				logger:     logrus.New(),
+				token:      &oauth2.Token{AccessToken: "access token"},
+				httpClient: tt.httpClient,
+			}
+			got, err := c.GetTamperProtection(context.Background(), tt.tenant, tt.endpointID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTamperProtection() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestClient_SetTamperProtection(t *testing.T) {
+	a := assert.New(t)
+	var gotBody string
+	hc := httpClientWithRequestRecorder(200, `{"enabled": false}`, func(req *http.Request, body []byte) {
+		gotBody = string(body)
+	})
+	c := &Client{
+		logger:     logrus.New(),
+		token:      &oauth2.Token{AccessToken: "access token"},
+		httpClient: hc,
+	}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	got, err := c.SetTamperProtection(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", false)
+	a.NoError(err)
+	a.Equal(TamperProtection{Enabled: false}, got)
+	a.JSONEq(`{"enabled": false}`, gotBody)
+
+	_, err = c.RegenerateTamperPassword(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605")
+	a.NoError(err)
+	a.JSONEq(`{"regeneratePassword": true}`, gotBody)
+}
@@ -0,0 +1,350 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Web control settings for the sophos central ENDPOINT API
+https://developer.sophos.com/docs/endpoint-v1/1/overview
+
+GET		/settings/web-control/local-sites
+POST	/settings/web-control/local-sites
+GET		/settings/web-control/local-sites/{localSiteId}
+PATCH	/settings/web-control/local-sites/{localSiteId}
+DELETE	/settings/web-control/local-sites/{localSiteId}
+
+GET		/settings/web-control/tls-decryption
+PATCH	/settings/web-control/tls-decryption
+*/
+
+const (
+	localSitesPath    = "/endpoint/v1/settings/web-control/local-sites"
+	tlsDecryptionPath = "/endpoint/v1/settings/web-control/tls-decryption"
+)
+
+// GetLocalSites returns every local site definition of a tenant.
+func (c *Client) GetLocalSites(ctx context.Context, tenant TenantsResponseItem) (LocalSites, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/local-sites
+
+	var all LocalSites
+	err := c.tenantPages(ctx, tenant, localSitesPath, nil, func(b []byte) (Pages, error) {
+		ls, err := UnmarshalLocalSites(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, ls.Items...)
+		all.Pages = ls.Pages
+		return ls.Pages, nil
+	})
+	if err != nil {
+		return LocalSites{}, err
+	}
+
+	return all, nil
+}
+
+// GetLocalSite returns one local site definition by id.
+func (c *Client) GetLocalSite(ctx context.Context, tenant TenantsResponseItem, localSiteID string) (LocalSite, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/local-sites/{localSiteId}
+
+	if _, err := uuid.Parse(localSiteID); err != nil {
+		return LocalSite{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/%s", localSitesPath, localSiteID), nil, nil)
+	if err != nil {
+		return LocalSite{}, err
+	}
+
+	return UnmarshalLocalSite(b)
+}
+
+// AddLocalSite recategorises or tags a site.
+func (c *Client) AddLocalSite(ctx context.Context, tenant TenantsResponseItem, lsr LocalSiteRequest) (LocalSite, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/local-sites
+
+	if err := lsr.Validate(); err != nil {
+		return LocalSite{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", localSitesPath, nil, lsr)
+	if err != nil {
+		return LocalSite{}, err
+	}
+
+	return UnmarshalLocalSite(b)
+}
+
+// UpdateLocalSite changes a local site definition.
+func (c *Client) UpdateLocalSite(ctx context.Context, tenant TenantsResponseItem, localSiteID string, lsr LocalSiteRequest) (LocalSite, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/local-sites/{localSiteId}
+
+	if _, err := uuid.Parse(localSiteID); err != nil {
+		return LocalSite{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/%s", localSitesPath, localSiteID), nil, lsr)
+	if err != nil {
+		return LocalSite{}, err
+	}
+
+	return UnmarshalLocalSite(b)
+}
+
+// DeleteLocalSite removes a local site definition.
+func (c *Client) DeleteLocalSite(ctx context.Context, tenant TenantsResponseItem, localSiteID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/local-sites/{localSiteId}
+
+	if _, err := uuid.Parse(localSiteID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrItemID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/%s", localSitesPath, localSiteID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// ImportLocalSitesCSV adds the local sites read from r, see ParseLocalSitesCSV for the format.
+// Invalid sites are skipped with their validation error and sites whose url is already defined
+// with ErrDuplicateItem.  One result is returned per row so a failed row does not stop the rest
+// of the import; only a file that cannot be read as CSV fails the whole import.
+func (c *Client) ImportLocalSitesCSV(ctx context.Context, tenant TenantsResponseItem, r io.Reader) ([]LocalSiteImportResult, error) {
+
+	rows, err := parseLocalSitesCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.GetLocalSites(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]string, len(existing.Items))
+	for _, ls := range existing.Items {
+		known[strings.ToLower(ls.URL)] = ls.ID
+	}
+
+	results := make([]LocalSiteImportResult, 0, len(rows))
+	for _, row := range rows {
+		lsr := row.request
+		res := LocalSiteImportResult{Row: row.line, Request: lsr, Err: row.err}
+		if res.Err != nil {
+			results = append(results, res)
+			continue
+		}
+		if id, ok := known[strings.ToLower(lsr.URL)]; ok {
+			res.Err = fmt.Errorf("%w: %s", ErrDuplicateItem, id)
+			results = append(results, res)
+			continue
+		}
+
+		res.Site, res.Err = c.AddLocalSite(ctx, tenant, lsr)
+		if res.Err == nil {
+			known[strings.ToLower(lsr.URL)] = res.Site.ID
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// ParseLocalSitesCSV reads local site definitions from CSV.  The first row is a header
+// naming the columns, in any order: url (required), categoryId, tags and comment.
+// Multiple tags in one cell are separated with semicolons.
+func ParseLocalSitesCSV(r io.Reader) ([]LocalSiteRequest, error) {
+
+	rows, err := parseLocalSitesCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]LocalSiteRequest, 0, len(rows))
+	for _, row := range rows {
+		if row.err != nil {
+			return nil, fmt.Errorf("invalid local site on csv line %d: %w", row.line, row.err)
+		}
+		sites = append(sites, row.request)
+	}
+
+	return sites, nil
+}
+
+// localSiteRow is one data row of a local sites csv.  err is set when the site is invalid.
+type localSiteRow struct {
+	line    int
+	request LocalSiteRequest
+	err     error
+}
+
+// parseLocalSitesCSV reads every row of a local sites csv, keeping invalid sites with their
+// error.  It fails only when the csv itself cannot be read.
+func parseLocalSitesCSV(r io.Reader) ([]localSiteRow, error) {
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["url"]; !ok {
+		return nil, errors.New("csv header must include a url column")
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := cols[strings.ToLower(name)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []localSiteRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d: %w", line, err)
+		}
+
+		lsr := LocalSiteRequest{
+			URL:     cell(record, "url"),
+			Comment: cell(record, "comment"),
+		}
+		if cid := cell(record, "categoryId"); cid != "" {
+			id, err := strconv.Atoi(cid)
+			if err != nil {
+				rows = append(rows, localSiteRow{line: line, request: lsr, err: fmt.Errorf("invalid categoryId: %w", err)})
+				continue
+			}
+			lsr.CategoryID = &id
+		}
+		for _, tag := range strings.Split(cell(record, "tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				lsr.Tags = append(lsr.Tags, tag)
+			}
+		}
+
+		rows = append(rows, localSiteRow{line: line, request: lsr, err: lsr.Validate()})
+	}
+
+	return rows, nil
+}
+
+// Validate checks a url is set along with a category or at least one tag.
+func (lsr LocalSiteRequest) Validate() error {
+	if lsr.URL == "" {
+		return ErrMissingInput{Argument: "URL"}
+	}
+	if lsr.CategoryID == nil && len(lsr.Tags) == 0 {
+		return ErrMissingInput{Argument: "CategoryID or Tags"}
+	}
+	return nil
+}
+
+// GetTLSDecryptionSettings returns the tenant's SSL/TLS decryption settings and exclusions.
+func (c *Client) GetTLSDecryptionSettings(ctx context.Context, tenant TenantsResponseItem) (TLSDecryptionSettings, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/tls-decryption
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", tlsDecryptionPath, nil, nil)
+	if err != nil {
+		return TLSDecryptionSettings{}, err
+	}
+
+	return UnmarshalTLSDecryptionSettings(b)
+}
+
+// UpdateTLSDecryptionSettings replaces the tenant's SSL/TLS decryption settings and exclusions.
+func (c *Client) UpdateTLSDecryptionSettings(ctx context.Context, tenant TenantsResponseItem, tds TLSDecryptionSettings) (TLSDecryptionSettings, error) {
+	// https://api-{dataRegion}.central.sophos.com/endpoint/v1/settings/web-control/tls-decryption
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", tlsDecryptionPath, nil, tds)
+	if err != nil {
+		return TLSDecryptionSettings{}, err
+	}
+
+	return UnmarshalTLSDecryptionSettings(b)
+}
+
+func UnmarshalLocalSites(data []byte) (LocalSites, error) {
+	var r LocalSites
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LocalSites{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalLocalSite(data []byte) (LocalSite, error) {
+	var r LocalSite
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LocalSite{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalTLSDecryptionSettings(data []byte) (TLSDecryptionSettings, error) {
+	var r TLSDecryptionSettings
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return TLSDecryptionSettings{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type LocalSites struct {
+	Items []LocalSite `json:"items"`
+	Pages Pages       `json:"pages"`
+}
+
+type LocalSite struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	CategoryID *int     `json:"categoryId,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Comment    string   `json:"comment,omitempty"`
+}
+
+type LocalSiteRequest struct {
+	URL        string   `json:"url,omitempty"`
+	CategoryID *int     `json:"categoryId,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Comment    string   `json:"comment,omitempty"`
+}
+
+// LocalSiteImportResult is the outcome of importing one csv row.  Row is the csv line number,
+// counting the header as line 1, as in the errors of ParseLocalSitesCSV.
+type LocalSiteImportResult struct {
+	Row     int
+	Request LocalSiteRequest
+	Site    LocalSite
+	Err     error
+}
+
+type TLSDecryptionSettings struct {
+	Enabled             bool     `json:"enabled"`
+	ExcludedWebsites    []string `json:"excludedWebsites"`
+	ExcludedCategoryIDs []int    `json:"excludedCategoryIds"`
+}
@@ -0,0 +1,78 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestParseLocalSitesCSV(t *testing.T) {
+	a := assert.New(t)
+
+	intranet := 50
+	sites, err := ParseLocalSitesCSV(strings.NewReader(`url, tags, categoryId, comment
+intranet.example.com, internal;hr, 50, "HR portal, moved in May"
+build.example.com, internal, ,
+`))
+	a.NoError(err)
+	a.Equal([]LocalSiteRequest{
+		{URL: "intranet.example.com", CategoryID: &intranet, Tags: []string{"internal", "hr"}, Comment: "HR portal, moved in May"},
+		{URL: "build.example.com", Tags: []string{"internal"}},
+	}, sites)
+
+	_, err = ParseLocalSitesCSV(strings.NewReader("tags,comment\ninternal,none\n"))
+	a.Error(err)
+
+	_, err = ParseLocalSitesCSV(strings.NewReader("url,categoryId\nintranet.example.com,hr\n"))
+	a.Error(err)
+
+	_, err = ParseLocalSitesCSV(strings.NewReader("url,comment\nintranet.example.com,no category or tags\n"))
+	a.Error(err)
+}
+
+func TestClient_ImportLocalSitesCSV(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var posted []string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/endpoint/v1/settings/web-control/local-sites", req.URL.Path)
+			body := `{"items": [{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "url": "Intranet.example.com", "tags": ["internal"]}], "pages": {"current": 1, "total": 1}}`
+			if req.Method == "POST" {
+				var lsr LocalSiteRequest
+				b, _ := ioutil.ReadAll(req.Body)
+				a.NoError(json.Unmarshal(b, &lsr))
+				posted = append(posted, lsr.URL)
+				body = `{"id": "03b43abe-4f41-4734-b6d6-70b2fbdc2504", "url": "` + lsr.URL + `", "tags": ["internal"]}`
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	results, err := c.ImportLocalSitesCSV(context.Background(), tenant, strings.NewReader(`url,tags,categoryId
+hr.example.com,,hr
+intranet.example.com,internal,
+build.example.com,internal,
+`))
+	a.NoError(err)
+	a.Len(results, 3)
+	a.Equal([]int{2, 3, 4}, []int{results[0].Row, results[1].Row, results[2].Row})
+	a.Error(results[0].Err)
+	a.ErrorIs(results[1].Err, ErrDuplicateItem)
+	a.NoError(results[2].Err)
+	a.Equal("03b43abe-4f41-4734-b6d6-70b2fbdc2504", results[2].Site.ID)
+	a.Equal([]string{"build.example.com"}, posted)
+
+	_, err = c.ImportLocalSitesCSV(context.Background(), tenant, strings.NewReader("tags,comment\ninternal,none\n"))
+	a.Error(err)
+}
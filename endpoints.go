@@ -85,15 +85,15 @@ const (
 type LockdownStatus string
 const(
 	CreatingWhiteList LockdownStatus = "creatingWhiteList"
-	Installing = "installing"
-	Locked = "locked"
-	LDSNotInstalled = "notInstalled"
-	Registering = "registering"
-	Starting = "starting"
-	Stopping = "stopping"
-	Unavailable = "unavailable"
-	Uninstalled = "uninstalled"
-	Unlocked = "unlocked"
+	Installing LockdownStatus = "installing"
+	Locked LockdownStatus = "locked"
+	LDSNotInstalled LockdownStatus = "notInstalled"
+	Registering LockdownStatus = "registering"
+	Starting LockdownStatus = "starting"
+	Stopping LockdownStatus = "stopping"
+	Unavailable LockdownStatus = "unavailable"
+	Uninstalled LockdownStatus = "uninstalled"
+	Unlocked LockdownStatus = "unlocked"
 )
 type LockdownUpdateStatus string
 const(
@@ -132,6 +132,16 @@ func (r *Endpoints) Marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// Find returns the endpoint with the given id.
+func (r Endpoints) Find(id string) (EndpointItem, bool) {
+	for _, ep := range r.Item {
+		if ep.ID == id {
+			return ep, true
+		}
+	}
+	return EndpointItem{}, false
+}
+
 
 type Endpoints struct{
 	Item []EndpointItem `json:"items"`
@@ -179,7 +189,9 @@ type AssociatedPerson struct {
 }
 
 type Group struct {
-	Name string `json:"name"`
+	ID   string            `json:"id,omitempty"`
+	Name string            `json:"name"`
+	Type EndpointGroupType `json:"type,omitempty"`
 }
 
 type Health struct {
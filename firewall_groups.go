@@ -0,0 +1,205 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Firewall groups for the sophos central FIREWALL API
+https://developer.sophos.com/docs/firewall-v1/1/overview
+
+GET		/firewall-groups
+POST	/firewall-groups
+GET		/firewall-groups/{groupId}
+PATCH	/firewall-groups/{groupId}
+DELETE	/firewall-groups/{groupId}
+
+Membership is changed through PATCH, with the firewalls to assign and unassign.
+*/
+
+// GetFirewallGroups returns the firewall groups of a tenant.
+// Allowed query params: recurseSubgroups, search, page, pageSize, pageTotal
+func (c *Client) GetFirewallGroups(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (FirewallGroups, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewall-groups
+
+	var all FirewallGroups
+	err := c.tenantPages(ctx, tenant, "/firewall/v1/firewall-groups", queryParams, func(b []byte) (Pages, error) {
+		fgs, err := UnmarshalFirewallGroups(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, fgs.Items...)
+		all.Pages = fgs.Pages
+		return fgs.Pages, nil
+	})
+	if err != nil {
+		return FirewallGroups{}, err
+	}
+
+	return all, nil
+}
+
+// GetFirewallGroup returns one firewall group by id.
+func (c *Client) GetFirewallGroup(ctx context.Context, tenant TenantsResponseItem, groupID string) (FirewallGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewall-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return FirewallGroup{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/firewall/v1/firewall-groups/%s", groupID), nil, nil)
+	if err != nil {
+		return FirewallGroup{}, err
+	}
+
+	return UnmarshalFirewallGroup(b)
+}
+
+// CreateFirewallGroup creates a firewall group, optionally with firewalls already in it.
+func (c *Client) CreateFirewallGroup(ctx context.Context, tenant TenantsResponseItem, cfr CreateFirewallGroupRequest) (FirewallGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewall-groups
+
+	if cfr.Name == "" {
+		return FirewallGroup{}, ErrMissingInput{Argument: "Name"}
+	}
+	if len(cfr.AssignFirewalls) > 0 && !areValidUUIDs(cfr.AssignFirewalls) {
+		return FirewallGroup{}, ErrFirewallID
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/firewall/v1/firewall-groups", nil, cfr)
+	if err != nil {
+		return FirewallGroup{}, err
+	}
+
+	return UnmarshalFirewallGroup(b)
+}
+
+// UpdateFirewallGroup renames a firewall group and/or changes which firewalls are in it.
+func (c *Client) UpdateFirewallGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, ufr UpdateFirewallGroupRequest) (FirewallGroup, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewall-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return FirewallGroup{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+	if err := ufr.validate(); err != nil {
+		return FirewallGroup{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/firewall/v1/firewall-groups/%s", groupID), nil, ufr)
+	if err != nil {
+		return FirewallGroup{}, err
+	}
+
+	return UnmarshalFirewallGroup(b)
+}
+
+// AddFirewallsToGroup moves firewalls into a firewall group.
+func (c *Client) AddFirewallsToGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, firewallIDs []string) (FirewallGroup, error) {
+	if !areValidUUIDs(firewallIDs) {
+		return FirewallGroup{}, ErrFirewallID
+	}
+	return c.UpdateFirewallGroup(ctx, tenant, groupID, UpdateFirewallGroupRequest{AssignFirewalls: firewallIDs})
+}
+
+// RemoveFirewallsFromGroup takes firewalls out of a firewall group.
+func (c *Client) RemoveFirewallsFromGroup(ctx context.Context, tenant TenantsResponseItem, groupID string, firewallIDs []string) (FirewallGroup, error) {
+	if !areValidUUIDs(firewallIDs) {
+		return FirewallGroup{}, ErrFirewallID
+	}
+	return c.UpdateFirewallGroup(ctx, tenant, groupID, UpdateFirewallGroupRequest{UnassignFirewalls: firewallIDs})
+}
+
+// DeleteFirewallGroup deletes a firewall group.  The firewalls in it are not deleted.
+func (c *Client) DeleteFirewallGroup(ctx context.Context, tenant TenantsResponseItem, groupID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewall-groups/{groupId}
+
+	if _, err := uuid.Parse(groupID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrGroupID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("/firewall/v1/firewall-groups/%s", groupID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// validate checks that the update changes something and that the firewall ids are valid.
+func (ufr UpdateFirewallGroupRequest) validate() error {
+	if ufr.Name == "" && len(ufr.AssignFirewalls) == 0 && len(ufr.UnassignFirewalls) == 0 {
+		return ErrMissingInput{Argument: "UpdateFirewallGroupRequest"}
+	}
+	if len(ufr.AssignFirewalls) > 0 && !areValidUUIDs(ufr.AssignFirewalls) {
+		return ErrFirewallID
+	}
+	if len(ufr.UnassignFirewalls) > 0 && !areValidUUIDs(ufr.UnassignFirewalls) {
+		return ErrFirewallID
+	}
+	return nil
+}
+
+func UnmarshalFirewallGroups(data []byte) (FirewallGroups, error) {
+	var r FirewallGroups
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return FirewallGroups{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalFirewallGroup(data []byte) (FirewallGroup, error) {
+	var r FirewallGroup
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return FirewallGroup{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type FirewallGroups struct {
+	Items []FirewallGroup `json:"items"`
+	Pages Pages           `json:"pages"`
+}
+
+type FirewallGroup struct {
+	ID                      string                 `json:"id"`
+	Name                    string                 `json:"name"`
+	ParentGroup             *FirewallGroupRef      `json:"parentGroup,omitempty"`
+	Firewalls               FirewallGroupFirewalls `json:"firewalls"`
+	ConfigImport            *FirewallConfigImport  `json:"configImport,omitempty"`
+	LockedByManagingAccount bool                   `json:"lockedByManagingAccount,omitempty"`
+	CreatedBy               *ItemCreatedBy         `json:"createdBy,omitempty"`
+	CreatedAt               *time.Time             `json:"createdAt,omitempty"`
+	UpdatedAt               *time.Time             `json:"updatedAt,omitempty"`
+	SubgroupCount           int                    `json:"subgroupCount,omitempty"`
+}
+
+type FirewallGroupFirewalls struct {
+	Total      int                `json:"total"`
+	ItemsCount int                `json:"itemsCount,omitempty"`
+	Items      []FirewallGroupRef `json:"items,omitempty"`
+}
+
+// FirewallConfigImport is the firewall a group's configuration was taken from.
+type FirewallConfigImport struct {
+	SourceFirewallID string `json:"sourceFirewallId"`
+}
+
+type CreateFirewallGroupRequest struct {
+	Name            string                `json:"name"`
+	AssignFirewalls []string              `json:"assignFirewalls,omitempty"`
+	ConfigImport    *FirewallConfigImport `json:"configImport,omitempty"`
+}
+
+type UpdateFirewallGroupRequest struct {
+	Name              string   `json:"name,omitempty"`
+	AssignFirewalls   []string `json:"assignFirewalls,omitempty"`
+	UnassignFirewalls []string `json:"unassignFirewalls,omitempty"`
+}
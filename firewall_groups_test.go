@@ -0,0 +1,46 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_UpdateFirewallGroup(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var sent []string
+	hc := httpClientWithRequestRecorder(200, `{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "name": "Branches"}`, func(req *http.Request, body []byte) {
+		a.Equal("PATCH", req.Method)
+		a.Equal("/firewall/v1/firewall-groups/d2ba043d-7fcd-4158-a861-1ec2c01f3d14", req.URL.Path)
+		sent = append(sent, string(body))
+	})
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	fg, err := c.AddFirewallsToGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", []string{"bc893b97-86a8-41aa-b65c-910e11505605"})
+	a.NoError(err)
+	a.Equal("Branches", fg.Name)
+	_, err = c.RemoveFirewallsFromGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", []string{"03b43abe-4f41-4734-b6d6-70b2fbdc2504"})
+	a.NoError(err)
+	_, err = c.UpdateFirewallGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", UpdateFirewallGroupRequest{Name: "Branches"})
+	a.NoError(err)
+
+	a.Equal([]string{
+		`{"assignFirewalls":["bc893b97-86a8-41aa-b65c-910e11505605"]}`,
+		`{"unassignFirewalls":["03b43abe-4f41-4734-b6d6-70b2fbdc2504"]}`,
+		`{"name":"Branches"}`,
+	}, sent)
+
+	_, err = c.UpdateFirewallGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", UpdateFirewallGroupRequest{})
+	a.Error(err)
+	_, err = c.AddFirewallsToGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", nil)
+	a.Error(err)
+	_, err = c.UpdateFirewallGroup(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", UpdateFirewallGroupRequest{UnassignFirewalls: []string{"fw-hq"}})
+	a.Error(err)
+	a.Len(sent, 3)
+}
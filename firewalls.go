@@ -0,0 +1,369 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Implementation for sophos central FIREWALL API
+https://developer.sophos.com/docs/firewall-v1/1/overview
+URIs are relative to https://api-{dataRegion}.central.sophos.com/firewall/v1
+
+GET		/firewalls
+GET		/firewalls/{firewallId}
+PATCH	/firewalls/{firewallId}
+DELETE	/firewalls/{firewallId}
+POST	/firewalls/{firewallId}/action
+POST	/firewalls/actions/firmware-upgrade-check
+POST	/firewalls/actions/firmware-upgrade
+DELETE	/firewalls/actions/firmware-upgrade
+
+Firewall groups are in firewall_groups.go.
+*/
+
+// GetFirewalls returns the firewalls of a tenant.
+// Allowed query params: groupId, search, page, pageSize, pageTotal
+func (c *Client) GetFirewalls(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (Firewalls, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls
+
+	var all Firewalls
+	err := c.tenantPages(ctx, tenant, "/firewall/v1/firewalls", queryParams, func(b []byte) (Pages, error) {
+		fws, err := UnmarshalFirewalls(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, fws.Items...)
+		all.Pages = fws.Pages
+		return fws.Pages, nil
+	})
+	if err != nil {
+		return Firewalls{}, err
+	}
+
+	return all, nil
+}
+
+// GetFirewall returns one firewall by id.
+func (c *Client) GetFirewall(ctx context.Context, tenant TenantsResponseItem, firewallID string) (FirewallItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/{firewallId}
+
+	if _, err := uuid.Parse(firewallID); err != nil {
+		return FirewallItem{}, fmt.Errorf("%s: %w", ErrFirewallID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/firewall/v1/firewalls/%s", firewallID), nil, nil)
+	if err != nil {
+		return FirewallItem{}, err
+	}
+
+	return UnmarshalFirewallItem(b)
+}
+
+// UpdateFirewall changes the label and/or location of a firewall.
+func (c *Client) UpdateFirewall(ctx context.Context, tenant TenantsResponseItem, firewallID string, ufr UpdateFirewallRequest) (FirewallItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/{firewallId}
+
+	if _, err := uuid.Parse(firewallID); err != nil {
+		return FirewallItem{}, fmt.Errorf("%s: %w", ErrFirewallID, err)
+	}
+	if err := ufr.validate(); err != nil {
+		return FirewallItem{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("/firewall/v1/firewalls/%s", firewallID), nil, ufr)
+	if err != nil {
+		return FirewallItem{}, err
+	}
+
+	return UnmarshalFirewallItem(b)
+}
+
+// DeleteFirewall removes a firewall from Central.  The firewall itself is left running.
+func (c *Client) DeleteFirewall(ctx context.Context, tenant TenantsResponseItem, firewallID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/{firewallId}
+
+	if _, err := uuid.Parse(firewallID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrFirewallID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("/firewall/v1/firewalls/%s", firewallID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// ApproveFirewallManagement accepts a firewall that has asked to be managed from Central.
+func (c *Client) ApproveFirewallManagement(ctx context.Context, tenant TenantsResponseItem, firewallID string) (FirewallItem, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/{firewallId}/action
+
+	if _, err := uuid.Parse(firewallID); err != nil {
+		return FirewallItem{}, fmt.Errorf("%s: %w", ErrFirewallID, err)
+	}
+
+	body := FirewallActionRequest{Action: FirewallApproveManagement}
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/firewall/v1/firewalls/%s/action", firewallID), nil, body)
+	if err != nil {
+		return FirewallItem{}, err
+	}
+
+	return UnmarshalFirewallItem(b)
+}
+
+// CheckFirmwareUpgrades returns the firmware each of the firewalls can be upgraded to, and
+// the details of those versions.
+func (c *Client) CheckFirmwareUpgrades(ctx context.Context, tenant TenantsResponseItem, firewallIDs []string) (FirmwareUpgradeCheck, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/actions/firmware-upgrade-check
+
+	if !areValidUUIDs(firewallIDs) {
+		return FirmwareUpgradeCheck{}, ErrFirewallID
+	}
+
+	body := FirmwareUpgradeCheckRequest{Firewalls: firewallIDs}
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/firewall/v1/firewalls/actions/firmware-upgrade-check", nil, body)
+	if err != nil {
+		return FirmwareUpgradeCheck{}, err
+	}
+
+	return UnmarshalFirmwareUpgradeCheck(b)
+}
+
+// ScheduleFirmwareUpgrades schedules firmware upgrades.  An upgrade with no UpgradeAt starts
+// straight away.
+func (c *Client) ScheduleFirmwareUpgrades(ctx context.Context, tenant TenantsResponseItem, upgrades []FirmwareUpgrade) (FirmwareUpgrades, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/actions/firmware-upgrade
+
+	if len(upgrades) == 0 {
+		return FirmwareUpgrades{}, ErrMissingInput{Argument: "upgrades"}
+	}
+	for _, u := range upgrades {
+		if _, err := uuid.Parse(u.ID); err != nil {
+			return FirmwareUpgrades{}, fmt.Errorf("%s: %w", ErrFirewallID, err)
+		}
+		if u.UpgradeToVersion == "" {
+			return FirmwareUpgrades{}, ErrMissingInput{Argument: "UpgradeToVersion"}
+		}
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/firewall/v1/firewalls/actions/firmware-upgrade", nil, FirmwareUpgrades{Firewalls: upgrades})
+	if err != nil {
+		return FirmwareUpgrades{}, err
+	}
+
+	return UnmarshalFirmwareUpgrades(b)
+}
+
+// CancelFirmwareUpgrades cancels the scheduled firmware upgrades of the firewalls.
+func (c *Client) CancelFirmwareUpgrades(ctx context.Context, tenant TenantsResponseItem, firewallIDs []string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/firewall/v1/firewalls/actions/firmware-upgrade?ids=
+
+	if !areValidUUIDs(firewallIDs) {
+		return DeletedResponse{}, ErrFirewallID
+	}
+
+	qp := map[string]string{"ids": strings.Join(firewallIDs, ",")}
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", "/firewall/v1/firewalls/actions/firmware-upgrade", qp, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+// Find returns the firewall with the given id.
+func (r Firewalls) Find(id string) (FirewallItem, bool) {
+	for _, fw := range r.Items {
+		if fw.ID == id {
+			return fw, true
+		}
+	}
+	return FirewallItem{}, false
+}
+
+// FirewallForAlert returns the firewall in fws an xgFirewall alert was raised by.  The alert's
+// managed agent is the firewall.
+func FirewallForAlert(alert AlertItem, fws Firewalls) (FirewallItem, bool) {
+	if alert.Type != XGFirewall || alert.ManagedAgent.ID == nil {
+		return FirewallItem{}, false
+	}
+	return fws.Find(*alert.ManagedAgent.ID)
+}
+
+// GetFirewallForAlert fetches the firewall an xgFirewall alert was raised by.
+func (c *Client) GetFirewallForAlert(ctx context.Context, tenant TenantsResponseItem, alert AlertItem) (FirewallItem, error) {
+	if alert.Type != XGFirewall {
+		return FirewallItem{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "alert.Type"}, Value: alert.Type}
+	}
+	if alert.ManagedAgent.ID == nil {
+		return FirewallItem{}, ErrMissingInput{Argument: "alert.ManagedAgent.ID"}
+	}
+	return c.GetFirewall(ctx, tenant, *alert.ManagedAgent.ID)
+}
+
+func (ufr UpdateFirewallRequest) validate() error {
+	if ufr.Label == "" && ufr.GeoLocation == nil {
+		return ErrMissingInput{Argument: "UpdateFirewallRequest"}
+	}
+	if gl := ufr.GeoLocation; gl != nil {
+		if gl.Latitude < -90 || gl.Latitude > 90 {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Latitude"}, Value: gl.Latitude}
+		}
+		if gl.Longitude < -180 || gl.Longitude > 180 {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Longitude"}, Value: gl.Longitude}
+		}
+	}
+	return nil
+}
+
+func UnmarshalFirewalls(data []byte) (Firewalls, error) {
+	var r Firewalls
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return Firewalls{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalFirewallItem(data []byte) (FirewallItem, error) {
+	var r FirewallItem
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return FirewallItem{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalFirmwareUpgradeCheck(data []byte) (FirmwareUpgradeCheck, error) {
+	var r FirmwareUpgradeCheck
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return FirmwareUpgradeCheck{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalFirmwareUpgrades(data []byte) (FirmwareUpgrades, error) {
+	var r FirmwareUpgrades
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return FirmwareUpgrades{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type Firewalls struct {
+	Items []FirewallItem `json:"items"`
+	Pages Pages          `json:"pages"`
+}
+
+type FirewallItem struct {
+	ID                    string               `json:"id"`
+	Name                  string               `json:"name,omitempty"`
+	Hostname              string               `json:"hostname"`
+	SerialNumber          string               `json:"serialNumber"`
+	Model                 string               `json:"model,omitempty"`
+	FirmwareVersion       string               `json:"firmwareVersion,omitempty"`
+	ExternalIpv4Addresses []string             `json:"externalIpv4Addresses,omitempty"`
+	Capabilities          []string             `json:"capabilities,omitempty"`
+	Status                FirewallStatus       `json:"status"`
+	Cluster               *FirewallCluster     `json:"cluster,omitempty"`
+	Group                 *FirewallGroupRef    `json:"group,omitempty"`
+	GeoLocation           *FirewallGeoLocation `json:"geoLocation,omitempty"`
+	Tenant                *Tenant              `json:"tenant,omitempty"`
+	StateChangedAt        *time.Time           `json:"stateChangedAt,omitempty"`
+	CreatedAt             *time.Time           `json:"createdAt,omitempty"`
+	UpdatedAt             *time.Time           `json:"updatedAt,omitempty"`
+}
+
+type FirewallStatus struct {
+	Managing  FirewallManagingStatus  `json:"managing"`
+	Reporting FirewallReportingStatus `json:"reporting"`
+	Connected bool                    `json:"connected"`
+	Suspended bool                    `json:"suspended"`
+}
+
+type FirewallManagingStatus string
+
+const (
+	FirewallApproved        FirewallManagingStatus = "approved"
+	FirewallPendingApproval FirewallManagingStatus = "pendingApproval"
+)
+
+type FirewallReportingStatus string
+
+const (
+	FirewallReportingApproved        FirewallReportingStatus = "approved"
+	FirewallReportingPendingApproval FirewallReportingStatus = "pendingApproval"
+)
+
+type FirewallCluster struct {
+	ID     string `json:"id"`
+	Mode   string `json:"mode"`
+	Status string `json:"status"`
+}
+
+type FirewallGroupRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type FirewallGeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type UpdateFirewallRequest struct {
+	Label       string               `json:"label,omitempty"`
+	GeoLocation *FirewallGeoLocation `json:"geoLocation,omitempty"`
+}
+
+type FirewallAction string
+
+const (
+	FirewallApproveManagement FirewallAction = "approveManagement"
+)
+
+type FirewallActionRequest struct {
+	Action FirewallAction `json:"action"`
+}
+
+type FirmwareUpgradeCheckRequest struct {
+	Firewalls []string `json:"firewalls"`
+}
+
+type FirmwareUpgradeCheck struct {
+	Firewalls        []FirewallFirmware `json:"firewalls"`
+	FirmwareVersions []FirmwareVersion  `json:"firmwareVersions"`
+}
+
+// FirewallFirmware is the firmware a firewall runs and the versions it can be upgraded to.
+type FirewallFirmware struct {
+	ID               string   `json:"id"`
+	FirmwareVersion  string   `json:"firmwareVersion"`
+	UpgradeToVersion []string `json:"upgradeToVersion"`
+}
+
+type FirmwareVersion struct {
+	Version string   `json:"version"`
+	Size    string   `json:"size,omitempty"`
+	Bugs    []string `json:"bugs,omitempty"`
+	News    []string `json:"news,omitempty"`
+}
+
+type FirmwareUpgrades struct {
+	Firewalls []FirmwareUpgrade `json:"firewalls"`
+}
+
+type FirmwareUpgrade struct {
+	ID               string     `json:"id"`
+	UpgradeToVersion string     `json:"upgradeToVersion"`
+	UpgradeAt        *time.Time `json:"upgradeAt,omitempty"`
+}
@@ -0,0 +1,61 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestFirewallForAlert(t *testing.T) {
+	a := assert.New(t)
+
+	fws, err := UnmarshalFirewalls([]byte(`{"items": [
+		{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "hostname": "fw-hq", "serialNumber": "C01001ABCDEF", "firmwareVersion": "SFOS 19.0.1 MR-1",
+		 "status": {"managing": "approved", "reporting": "approved", "connected": true, "suspended": false},
+		 "geoLocation": {"latitude": 51.75, "longitude": -1.25}}
+	], "pages": {"current": 1, "total": 1}}`))
+	a.NoError(err)
+
+	id := "bc893b97-86a8-41aa-b65c-910e11505605"
+	fw, ok := FirewallForAlert(AlertItem{Type: XGFirewall, ManagedAgent: ManagedAgent{ID: &id}}, fws)
+	a.True(ok)
+	a.Equal("fw-hq", fw.Hostname)
+	a.True(fw.Status.Connected)
+
+	_, ok = FirewallForAlert(AlertItem{Type: "Event::Endpoint::Threat::Detected", ManagedAgent: ManagedAgent{ID: &id}}, fws)
+	a.False(ok)
+	_, ok = FirewallForAlert(AlertItem{Type: XGFirewall}, fws)
+	a.False(ok)
+}
+
+func TestClient_ScheduleFirmwareUpgrades(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	var gotPath, gotBody string
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: httpClientWithRequestRecorder(200,
+		`{"firewalls": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "upgradeToVersion": "SFOS 19.5.1", "upgradeAt": "2021-06-01T02:00:00Z"}]}`,
+		func(req *http.Request, body []byte) {
+			gotPath = req.URL.Path
+			gotBody = string(body)
+		})}
+
+	at := mustParseTime("2021-06-01T02:00:00Z", time.RFC3339)
+	fu, err := c.ScheduleFirmwareUpgrades(context.Background(), tenant, []FirmwareUpgrade{
+		{ID: "bc893b97-86a8-41aa-b65c-910e11505605", UpgradeToVersion: "SFOS 19.5.1", UpgradeAt: &at},
+	})
+	a.NoError(err)
+	a.Equal("/firewall/v1/firewalls/actions/firmware-upgrade", gotPath)
+	a.JSONEq(`{"firewalls": [{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "upgradeToVersion": "SFOS 19.5.1", "upgradeAt": "2021-06-01T02:00:00Z"}]}`, gotBody)
+	a.Len(fu.Firewalls, 1)
+
+	_, err = c.ScheduleFirmwareUpgrades(context.Background(), tenant, []FirmwareUpgrade{{ID: "bc893b97-86a8-41aa-b65c-910e11505605"}})
+	a.Error(err)
+	_, err = c.UpdateFirewall(context.Background(), tenant, "bc893b97-86a8-41aa-b65c-910e11505605", UpdateFirewallRequest{GeoLocation: &FirewallGeoLocation{Latitude: 91}})
+	a.Error(err)
+}
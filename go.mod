@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	github.com/google/uuid v1.2.0
+	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/oauth2 v0.0.0-20210427180440-81ed05c6b58c
@@ -0,0 +1,401 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Implementation for sophos central LIVE DISCOVER API
+https://developer.sophos.com/docs/live-discover-v1/1/overview
+
+GET		/queries
+GET		/queries/{queryId}
+GET		/queries/categories
+GET		/queries/categories/{categoryId}
+GET		/queries/runs
+POST	/queries/runs
+GET		/queries/runs/{runId}
+POST	/queries/runs/{runId}/cancel
+GET		/queries/runs/{runId}/endpoints
+GET		/queries/runs/{runId}/results
+GET		/queries/runs/{runId}/endpoints/{endpointId}/results
+*/
+
+// DefaultQueryPollInterval is how often query runs are checked when no interval is given.
+const DefaultQueryPollInterval = 5 * time.Second
+
+// GetLiveDiscoverCategories returns the categories of the query catalog.
+func (c *Client) GetLiveDiscoverCategories(ctx context.Context, tenant TenantsResponseItem) (QueryCategories, error) {
+	return c.GetQueryCategories(ctx, tenant, LiveDiscoverLibrary)
+}
+
+// GetLiveDiscoverCategory returns one category of the query catalog by id.
+func (c *Client) GetLiveDiscoverCategory(ctx context.Context, tenant TenantsResponseItem, categoryID string) (QueryCategory, error) {
+	return c.GetQueryCategory(ctx, tenant, LiveDiscoverLibrary, categoryID)
+}
+
+// GetLiveDiscoverQueries returns the saved queries of the query catalog.
+// Allowed query params: categoryId, search, searchFields, sort
+func (c *Client) GetLiveDiscoverQueries(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (SavedQueries, error) {
+	return c.GetSavedQueries(ctx, tenant, LiveDiscoverLibrary, queryParams)
+}
+
+// GetLiveDiscoverQuery returns one saved query by id.
+func (c *Client) GetLiveDiscoverQuery(ctx context.Context, tenant TenantsResponseItem, queryID string) (SavedQuery, error) {
+	return c.GetSavedQuery(ctx, tenant, LiveDiscoverLibrary, queryID)
+}
+
+// CreateLiveDiscoverRun starts a query on the endpoints lrr matches.  The query runs on each
+// endpoint as it comes online, so the run is returned straight away with a pending status.
+func (c *Client) CreateLiveDiscoverRun(ctx context.Context, tenant TenantsResponseItem, lrr LiveDiscoverRunRequest) (LiveDiscoverRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs
+
+	if err := lrr.validate(); err != nil {
+		return LiveDiscoverRun{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/live-discover/v1/queries/runs", nil, lrr)
+	if err != nil {
+		return LiveDiscoverRun{}, err
+	}
+
+	return UnmarshalLiveDiscoverRun(b)
+}
+
+// GetLiveDiscoverRuns returns the query runs of a tenant.
+// Allowed query params: createdAt, finishedAt, queryId, status, result, sort
+func (c *Client) GetLiveDiscoverRuns(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (LiveDiscoverRuns, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs
+
+	var all LiveDiscoverRuns
+	err := c.tenantPages(ctx, tenant, "/live-discover/v1/queries/runs", queryParams, func(b []byte) (Pages, error) {
+		lr, err := UnmarshalLiveDiscoverRuns(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, lr.Items...)
+		all.Pages = lr.Pages
+		return lr.Pages, nil
+	})
+	if err != nil {
+		return LiveDiscoverRuns{}, err
+	}
+
+	return all, nil
+}
+
+// GetLiveDiscoverRun returns the status of a query run.
+func (c *Client) GetLiveDiscoverRun(ctx context.Context, tenant TenantsResponseItem, runID string) (LiveDiscoverRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs/{runId}
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return LiveDiscoverRun{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/live-discover/v1/queries/runs/%s", runID), nil, nil)
+	if err != nil {
+		return LiveDiscoverRun{}, err
+	}
+
+	return UnmarshalLiveDiscoverRun(b)
+}
+
+// CancelLiveDiscoverRun stops a query run.  Results already returned by endpoints are kept.
+func (c *Client) CancelLiveDiscoverRun(ctx context.Context, tenant TenantsResponseItem, runID string) (LiveDiscoverRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs/{runId}/cancel
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return LiveDiscoverRun{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/live-discover/v1/queries/runs/%s/cancel", runID), nil, struct{}{})
+	if err != nil {
+		return LiveDiscoverRun{}, err
+	}
+
+	return UnmarshalLiveDiscoverRun(b)
+}
+
+// WaitForLiveDiscoverRun polls a query run every pollInterval until it has finished.  The last
+// status seen is returned along with any error, including when ctx is done.
+func (c *Client) WaitForLiveDiscoverRun(ctx context.Context, tenant TenantsResponseItem, runID string, pollInterval time.Duration) (LiveDiscoverRun, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultQueryPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		run, err := c.GetLiveDiscoverRun(ctx, tenant, runID)
+		if err != nil {
+			return run, err
+		}
+		if run.Status == QueryRunFinished {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetLiveDiscoverRunEndpoints returns the status of the query on each endpoint of a run.
+// Allowed query params: status, result
+func (c *Client) GetLiveDiscoverRunEndpoints(ctx context.Context, tenant TenantsResponseItem, runID string, queryParams map[string]string) (LiveDiscoverRunEndpoints, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs/{runId}/endpoints
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return LiveDiscoverRunEndpoints{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	var all LiveDiscoverRunEndpoints
+	err := c.tenantPages(ctx, tenant, fmt.Sprintf("/live-discover/v1/queries/runs/%s/endpoints", runID), queryParams, func(b []byte) (Pages, error) {
+		re, err := UnmarshalLiveDiscoverRunEndpoints(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, re.Items...)
+		all.Pages = re.Pages
+		return re.Pages, nil
+	})
+	if err != nil {
+		return LiveDiscoverRunEndpoints{}, err
+	}
+
+	return all, nil
+}
+
+// GetLiveDiscoverRunResults returns the rows of every endpoint of a run.  Each row has an
+// endpointId column naming the endpoint it came from.
+func (c *Client) GetLiveDiscoverRunResults(ctx context.Context, tenant TenantsResponseItem, runID string) (QueryResults, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs/{runId}/results
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return QueryResults{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	return c.queryResultPages(ctx, tenant, fmt.Sprintf("/live-discover/v1/queries/runs/%s/results", runID))
+}
+
+// GetLiveDiscoverEndpointResults returns the rows one endpoint returned for a run.
+func (c *Client) GetLiveDiscoverEndpointResults(ctx context.Context, tenant TenantsResponseItem, runID, endpointID string) (QueryResults, error) {
+	// https://api-{dataRegion}.central.sophos.com/live-discover/v1/queries/runs/{runId}/endpoints/{endpointId}/results
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return QueryResults{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return QueryResults{}, fmt.Errorf("%s: %w", ErrEndpointID, err)
+	}
+
+	return c.queryResultPages(ctx, tenant, fmt.Sprintf("/live-discover/v1/queries/runs/%s/endpoints/%s/results", runID, endpointID))
+}
+
+// queryResultPages reads every page of a result set into one.
+func (c *Client) queryResultPages(ctx context.Context, tenant TenantsResponseItem, path string) (QueryResults, error) {
+
+	var all QueryResults
+	err := c.tenantPages(ctx, tenant, path, nil, func(b []byte) (Pages, error) {
+		qr, err := UnmarshalQueryResults(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, qr.Items...)
+		if len(qr.Metadata.Columns) > 0 {
+			all.Metadata = qr.Metadata
+		}
+		all.Pages = qr.Pages
+		return qr.Pages, nil
+	})
+	if err != nil {
+		return QueryResults{}, err
+	}
+
+	return all, nil
+}
+
+// MatchEndpointsIn returns a selection of the endpoints in eps, such as the result of
+// GetEndpoints, for a query run.
+func MatchEndpointsIn(eps Endpoints) MatchEndpoints {
+	ids := make([]string, 0, len(eps.Item))
+	for _, ep := range eps.Item {
+		ids = append(ids, ep.ID)
+	}
+	return MatchEndpoints{Filters: []EndpointFilter{{IDs: ids}}}
+}
+
+func (lrr LiveDiscoverRunRequest) validate() error {
+	if len(lrr.MatchEndpoints.Filters) == 0 {
+		return ErrMissingInput{Argument: "MatchEndpoints"}
+	}
+	for _, f := range lrr.MatchEndpoints.Filters {
+		if err := f.validate(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case lrr.SavedQuery == nil && lrr.AdHocQuery == nil:
+		return ErrMissingInput{Argument: "SavedQuery or AdHocQuery"}
+	case lrr.SavedQuery != nil && lrr.AdHocQuery != nil:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "AdHocQuery"}, Value: "only one of SavedQuery and AdHocQuery can be set"}
+	case lrr.SavedQuery != nil:
+		if _, err := uuid.Parse(lrr.SavedQuery.QueryID); err != nil {
+			return fmt.Errorf("%s: %w", ErrQueryID, err)
+		}
+	case lrr.AdHocQuery.Template == "":
+		return ErrMissingInput{Argument: "AdHocQuery.Template"}
+	}
+	return nil
+}
+
+// validate checks the filter selects on at least one field, and that any ids are endpoint ids.
+func (f EndpointFilter) validate() error {
+	if len(f.IDs) == 0 && len(f.Types) == 0 && len(f.HealthStatuses) == 0 && f.HostnameContains == "" &&
+		f.GroupNameContains == "" && len(f.OS) == 0 && f.LastSeenBefore == "" && f.LastSeenAfter == "" && f.Search == "" {
+		return ErrMissingInput{Argument: "MatchEndpoints.Filters"}
+	}
+	if len(f.IDs) > 0 && !areValidUUIDs(f.IDs) {
+		return ErrEndpointID
+	}
+	return nil
+}
+
+func UnmarshalLiveDiscoverRuns(data []byte) (LiveDiscoverRuns, error) {
+	var r LiveDiscoverRuns
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LiveDiscoverRuns{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalLiveDiscoverRun(data []byte) (LiveDiscoverRun, error) {
+	var r LiveDiscoverRun
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LiveDiscoverRun{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalLiveDiscoverRunEndpoints(data []byte) (LiveDiscoverRunEndpoints, error) {
+	var r LiveDiscoverRunEndpoints
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return LiveDiscoverRunEndpoints{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// LiveDiscoverRunRequest starts a query run.  Exactly one of SavedQuery and AdHocQuery is set.
+type LiveDiscoverRunRequest struct {
+	MatchEndpoints MatchEndpoints  `json:"matchEndpoints"`
+	SavedQuery     *SavedQueryRef  `json:"savedQuery,omitempty"`
+	AdHocQuery     *AdHocQuery     `json:"adHocQuery,omitempty"`
+	Variables      []QueryVariable `json:"variables,omitempty"`
+}
+
+// MatchEndpoints selects the endpoints a query runs on.  An endpoint is selected when it
+// matches any of the filters.
+type MatchEndpoints struct {
+	Filters []EndpointFilter `json:"filters"`
+}
+
+// EndpointFilter matches endpoints on all of the fields set.
+type EndpointFilter struct {
+	IDs               []string           `json:"ids,omitempty"`
+	Types             []TypeEP           `json:"types,omitempty"`
+	HealthStatuses    []Overall          `json:"healthStatuses,omitempty"`
+	HostnameContains  string             `json:"hostnameContains,omitempty"`
+	GroupNameContains string             `json:"groupNameContains,omitempty"`
+	OS                []EndpointFilterOS `json:"os,omitempty"`
+	LastSeenBefore    string             `json:"lastSeenBefore,omitempty"`
+	LastSeenAfter     string             `json:"lastSeenAfter,omitempty"`
+	Search            string             `json:"search,omitempty"`
+}
+
+type EndpointFilterOS struct {
+	Platform Platform `json:"platform,omitempty"`
+	Name     string   `json:"name,omitempty"`
+}
+
+type SavedQueryRef struct {
+	QueryID string `json:"queryId"`
+}
+
+type AdHocQuery struct {
+	Name     string `json:"name,omitempty"`
+	Template string `json:"template"`
+}
+
+type LiveDiscoverRuns struct {
+	Items []LiveDiscoverRun `json:"items"`
+	Pages Pages             `json:"pages"`
+}
+
+type LiveDiscoverRun struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name,omitempty"`
+	QueryID        string              `json:"queryId,omitempty"`
+	Template       string              `json:"template,omitempty"`
+	Status         QueryRunStatus      `json:"status"`
+	Result         QueryRunResult      `json:"result"`
+	EndpointCounts QueryEndpointCounts `json:"endpointCounts"`
+	CreatedBy      ItemCreatedBy       `json:"createdBy"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	FinishedAt     *time.Time          `json:"finishedAt,omitempty"`
+}
+
+type QueryEndpointCounts struct {
+	Total    int `json:"total"`
+	Sent     int `json:"sent"`
+	Pending  int `json:"pending"`
+	Finished int `json:"finished"`
+	Failed   int `json:"failed"`
+}
+
+type QueryRunStatus string
+
+const (
+	QueryRunPending  QueryRunStatus = "pending"
+	QueryRunStarted  QueryRunStatus = "started"
+	QueryRunFinished QueryRunStatus = "finished"
+)
+
+type QueryRunResult string
+
+const (
+	QueryResultNotAvailable QueryRunResult = "notAvailable"
+	QueryResultSucceeded    QueryRunResult = "succeeded"
+	QueryResultFailed       QueryRunResult = "failed"
+	QueryResultTimedOut     QueryRunResult = "timedOut"
+	QueryResultCancelled    QueryRunResult = "cancelled"
+)
+
+type LiveDiscoverRunEndpoints struct {
+	Items []LiveDiscoverRunEndpoint `json:"items"`
+	Pages Pages                     `json:"pages"`
+}
+
+type LiveDiscoverRunEndpoint struct {
+	ID       string         `json:"id"`
+	Hostname string         `json:"hostname,omitempty"`
+	Status   QueryRunStatus `json:"status"`
+	Result   QueryRunResult `json:"result"`
+	Rows     int            `json:"rows"`
+	Error    string         `json:"error,omitempty"`
+}
@@ -0,0 +1,86 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_CreateLiveDiscoverRun(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	eps := Endpoints{Item: []EndpointItem{{ID: "bc893b97-86a8-41aa-b65c-910e11505605"}, {ID: "03b43abe-4f41-4734-b6d6-70b2fbdc2504"}}}
+
+	var gotBody string
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: httpClientWithRequestRecorder(200,
+		`{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "status": "pending", "result": "notAvailable", "endpointCounts": {"total": 2}, "createdAt": "2021-05-05T11:47:30.148Z"}`,
+		func(req *http.Request, body []byte) {
+			gotBody = string(body)
+		})}
+
+	run, err := c.CreateLiveDiscoverRun(context.Background(), tenant, LiveDiscoverRunRequest{
+		MatchEndpoints: MatchEndpointsIn(eps),
+		AdHocQuery:     &AdHocQuery{Template: "SELECT name, pid FROM processes"},
+	})
+	a.NoError(err)
+	a.Equal(QueryRunPending, run.Status)
+	a.Equal(2, run.EndpointCounts.Total)
+	a.JSONEq(`{"matchEndpoints": {"filters": [{"ids": ["bc893b97-86a8-41aa-b65c-910e11505605", "03b43abe-4f41-4734-b6d6-70b2fbdc2504"]}]},
+		"adHocQuery": {"template": "SELECT name, pid FROM processes"}}`, gotBody)
+
+	_, err = c.CreateLiveDiscoverRun(context.Background(), tenant, LiveDiscoverRunRequest{MatchEndpoints: MatchEndpointsIn(eps)})
+	a.Error(err)
+}
+
+func TestLiveDiscoverRunRequest_validate(t *testing.T) {
+	a := assert.New(t)
+
+	query := &AdHocQuery{Template: "SELECT 1"}
+	a.NoError(LiveDiscoverRunRequest{MatchEndpoints: MatchEndpoints{Filters: []EndpointFilter{{HostnameContains: "web", Types: []TypeEP{ServerEP}}}}, AdHocQuery: query}.validate())
+	a.NoError(LiveDiscoverRunRequest{MatchEndpoints: MatchEndpoints{Filters: []EndpointFilter{{IDs: []string{"bc893b97-86a8-41aa-b65c-910e11505605"}}}}, AdHocQuery: query}.validate())
+	a.Error(LiveDiscoverRunRequest{MatchEndpoints: MatchEndpoints{Filters: []EndpointFilter{{IDs: []string{"not an id"}}}}, AdHocQuery: query}.validate())
+	a.Error(LiveDiscoverRunRequest{MatchEndpoints: MatchEndpoints{Filters: []EndpointFilter{{}}}, AdHocQuery: query}.validate())
+	a.Error(LiveDiscoverRunRequest{AdHocQuery: query}.validate())
+}
+
+func TestQueryResults_Value(t *testing.T) {
+	a := assert.New(t)
+
+	qr, err := UnmarshalQueryResults([]byte(`{
+		"items": [{"endpointId": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "lsass.exe", "pid": 672, "start_time": "1620215250",
+		           "sophos_pid": 9007199254740993, "is_elevated": "1", "cpu": 0.25, "path": null}],
+		"metadata": {"columns": [{"name": "name", "type": "text"}, {"name": "pid", "type": "integer"}, {"name": "start_time", "type": "dateTime"},
+		                         {"name": "sophos_pid", "type": "bigint"}, {"name": "is_elevated", "type": "boolean"}, {"name": "cpu", "type": "double"},
+		                         {"name": "path", "type": "text"}]},
+		"pages": {"current": 1, "total": 1}}`))
+	a.NoError(err)
+
+	row := qr.Items[0]
+	tests := []struct {
+		column string
+		want   interface{}
+	}{
+		{"name", "lsass.exe"},
+		{"pid", int64(672)},
+		{"start_time", time.Unix(1620215250, 0).UTC()},
+		{"sophos_pid", int64(9007199254740993)},
+		{"is_elevated", true},
+		{"cpu", 0.25},
+		{"path", nil},
+	}
+	for _, tt := range tests {
+		got, err := qr.Value(row, tt.column)
+		a.NoError(err, tt.column)
+		a.Equal(tt.want, got, tt.column)
+	}
+
+	col, ok := qr.Column("sophos_pid")
+	a.True(ok)
+	a.Equal(ColumnBigInt, col.Type)
+}
@@ -0,0 +1,254 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+/*
+
+Result sets of Live Discover, XDR and Data Lake queries.
+
+Rows are returned as json objects keyed by column name, with the name and type of each
+column in the metadata of the result.  Numbers are kept as json.Number so 64 bit values,
+such as osquery's bigint columns, are not rounded.
+*/
+
+type QueryColumnType string
+
+const (
+	ColumnText     QueryColumnType = "text"
+	ColumnInteger  QueryColumnType = "integer"
+	ColumnBigInt   QueryColumnType = "bigint"
+	ColumnDouble   QueryColumnType = "double"
+	ColumnBoolean  QueryColumnType = "boolean"
+	ColumnDateTime QueryColumnType = "dateTime"
+)
+
+type QueryResults struct {
+	Items    []QueryRow    `json:"items"`
+	Metadata QueryMetadata `json:"metadata"`
+	Pages    Pages         `json:"pages"`
+}
+
+type QueryMetadata struct {
+	Columns []QueryColumn `json:"columns"`
+}
+
+type QueryColumn struct {
+	Name string          `json:"name"`
+	Type QueryColumnType `json:"type"`
+}
+
+// QueryRow is one row of a result set, keyed by column name.
+type QueryRow map[string]interface{}
+
+func UnmarshalQueryResults(data []byte) (QueryResults, error) {
+	var r QueryResults
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&r); err != nil {
+		return QueryResults{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// Column returns the metadata of the named column.
+func (qr QueryResults) Column(name string) (QueryColumn, bool) {
+	for _, c := range qr.Metadata.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return QueryColumn{}, false
+}
+
+// Value returns the value of a column of row converted to the go type of the column: string,
+// int64, float64, bool or time.Time.  Null values and columns the row does not have are nil.
+func (qr QueryResults) Value(row QueryRow, column string) (interface{}, error) {
+	if row[column] == nil {
+		return nil, nil
+	}
+
+	col, _ := qr.Column(column)
+	var v interface{}
+	var ok bool
+	switch col.Type {
+	case ColumnInteger, ColumnBigInt:
+		v, ok = row.Int64(column)
+	case ColumnDouble:
+		v, ok = row.Float64(column)
+	case ColumnBoolean:
+		v, ok = row.Bool(column)
+	case ColumnDateTime:
+		v, ok = row.Time(column)
+	default:
+		v, ok = row.String(column)
+	}
+	if !ok {
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: column}, Value: row[column]}
+	}
+	return v, nil
+}
+
+// String returns a column as text.  Numbers and booleans are formatted.
+func (r QueryRow) String(column string) (string, bool) {
+	switch v := r[column].(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// Int64 returns an integer column.  osquery returns some numbers as text, so numeric text is
+// converted too.
+func (r QueryRow) Int64(column string) (int64, bool) {
+	switch v := r[column].(type) {
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case float64:
+		return int64(v), v == float64(int64(v))
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Float64 returns a numeric column.
+func (r QueryRow) Float64(column string) (float64, bool) {
+	switch v := r[column].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Bool returns a boolean column.  osquery's 0 and 1 are read as false and true.
+func (r QueryRow) Bool(column string) (bool, bool) {
+	switch v := r[column].(type) {
+	case bool:
+		return v, true
+	case json.Number, string:
+		s, _ := r.String(column)
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// Time returns a date time column, given either as RFC 3339 text or as unix seconds.
+func (r QueryRow) Time(column string) (time.Time, bool) {
+	if s, ok := r[column].(string); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, true
+		}
+	}
+	if i, ok := r.Int64(column); ok {
+		return time.Unix(i, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// QueryResultIterator streams the rows of a result set a page at a time, so large results are
+// never held in memory at once.  Call Next until it returns false, then check Err.
+//
+//	it := c.XDRQueryResults(ctx, tenant, runID, 1000)
+//	for it.Next() {
+//		row := it.Row()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type QueryResultIterator struct {
+	c        *Client
+	ctx      context.Context
+	tenant   TenantsResponseItem
+	path     string
+	qp       map[string]string
+	page     QueryResults
+	i        int
+	more     bool
+	row      QueryRow
+	metadata QueryMetadata
+	err      error
+}
+
+func (c *Client) newQueryResultIterator(ctx context.Context, tenant TenantsResponseItem, path string, pageSize int, err error) *QueryResultIterator {
+	qp := map[string]string{"pageTotal": "true"}
+	if pageSize > 0 {
+		qp["pageSize"] = strconv.Itoa(pageSize)
+	}
+	return &QueryResultIterator{c: c, ctx: ctx, tenant: tenant, path: path, qp: qp, more: err == nil, err: err}
+}
+
+// Next moves to the next row, fetching the next page when the current one is used up.  It
+// returns false at the end of the results or on an error.
+func (it *QueryResultIterator) Next() bool {
+	for it.i >= len(it.page.Items) {
+		if !it.more {
+			return false
+		}
+
+		b, err := it.c.tenantRequest(it.ctx, it.tenant, "GET", it.path, it.qp, nil)
+		if err != nil {
+			it.err, it.more = err, false
+			return false
+		}
+		qr, err := UnmarshalQueryResults(b)
+		if err != nil {
+			it.err, it.more = err, false
+			return false
+		}
+
+		it.page, it.i = qr, 0
+		if len(qr.Metadata.Columns) > 0 {
+			it.metadata = qr.Metadata
+		}
+		it.more = qr.Pages.next(it.qp)
+	}
+
+	it.row = it.page.Items[it.i]
+	it.i++
+	return true
+}
+
+// Row returns the current row.
+func (it *QueryResultIterator) Row() QueryRow {
+	return it.row
+}
+
+// Metadata returns the columns of the results, known once Next has been called.
+func (it *QueryResultIterator) Metadata() QueryMetadata {
+	return it.metadata
+}
+
+// Value returns a column of the current row converted as QueryResults.Value does.
+func (it *QueryResultIterator) Value(column string) (interface{}, error) {
+	return QueryResults{Metadata: it.metadata}.Value(it.row, column)
+}
+
+// Err returns the error that stopped Next, if any.
+func (it *QueryResultIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,85 @@
+package sophoscentral
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit paces the requests a client makes to Central to perSecond on average, with
+// bursts of up to burst requests.  Central rate limits each tenant and api; going over gets
+// 429 responses, so long running jobs such as the syslog forwarder should set a limit.
+func WithRateLimit(perSecond float64, burst int) func(*Client) {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+// waitForRateLimit blocks until the client's rate limit allows another request, or ctx is done.
+// It returns straight away for a client without a rate limit.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.limiter.wait(ctx)
+}
+
+// rateLimiter is a token bucket refilled at rate tokens a second up to burst tokens.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: perSecond, burst: float64(burst), tokens: float64(burst)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token if there is one and returns 0, otherwise it returns how long until
+// the next token is due.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,351 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Saved queries of the sophos central LIVE DISCOVER and XDR QUERY APIs
+https://developer.sophos.com/docs/live-discover-v1/1/overview
+https://developer.sophos.com/docs/xdr-query-v1/1/overview
+
+Both apis keep a library of saved queries under the same paths, relative to their own root.
+
+GET		/queries
+POST	/queries
+GET		/queries/{queryId}
+PATCH	/queries/{queryId}
+DELETE	/queries/{queryId}
+GET		/queries/categories
+GET		/queries/categories/{categoryId}
+
+Sophos' own queries can be run but not changed; only custom queries can be updated or deleted.
+Templates refer to their variables as $$name$$.
+*/
+
+// QueryLibrary is the root of the api whose saved queries are used.
+type QueryLibrary string
+
+const (
+	LiveDiscoverLibrary QueryLibrary = "/live-discover/v1"
+	DataLakeLibrary     QueryLibrary = "/xdr-query/v1"
+)
+
+// GetQueryCategories returns the query categories of a library.
+func (c *Client) GetQueryCategories(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary) (QueryCategories, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries/categories
+
+	if err := lib.validate(); err != nil {
+		return QueryCategories{}, err
+	}
+
+	var all QueryCategories
+	err := c.tenantPages(ctx, tenant, string(lib)+"/queries/categories", nil, func(b []byte) (Pages, error) {
+		qc, err := UnmarshalQueryCategories(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, qc.Items...)
+		all.Pages = qc.Pages
+		return qc.Pages, nil
+	})
+	if err != nil {
+		return QueryCategories{}, err
+	}
+
+	return all, nil
+}
+
+// GetQueryCategory returns one query category of a library by id.
+func (c *Client) GetQueryCategory(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, categoryID string) (QueryCategory, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries/categories/{categoryId}
+
+	if err := lib.validate(); err != nil {
+		return QueryCategory{}, err
+	}
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return QueryCategory{}, fmt.Errorf("%s: %w", ErrCategoryID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/queries/categories/%s", lib, categoryID), nil, nil)
+	if err != nil {
+		return QueryCategory{}, err
+	}
+
+	return UnmarshalQueryCategory(b)
+}
+
+// GetSavedQueries returns the saved queries of a library.
+// Allowed query params: categoryId, search, searchFields, sort
+func (c *Client) GetSavedQueries(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, queryParams map[string]string) (SavedQueries, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries
+
+	if err := lib.validate(); err != nil {
+		return SavedQueries{}, err
+	}
+
+	var all SavedQueries
+	err := c.tenantPages(ctx, tenant, string(lib)+"/queries", queryParams, func(b []byte) (Pages, error) {
+		sq, err := UnmarshalSavedQueries(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, sq.Items...)
+		all.Pages = sq.Pages
+		return sq.Pages, nil
+	})
+	if err != nil {
+		return SavedQueries{}, err
+	}
+
+	return all, nil
+}
+
+// GetSavedQuery returns one saved query of a library by id.
+func (c *Client) GetSavedQuery(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, queryID string) (SavedQuery, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries/{queryId}
+
+	if err := lib.validate(); err != nil {
+		return SavedQuery{}, err
+	}
+	if _, err := uuid.Parse(queryID); err != nil {
+		return SavedQuery{}, fmt.Errorf("%s: %w", ErrQueryID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("%s/queries/%s", lib, queryID), nil, nil)
+	if err != nil {
+		return SavedQuery{}, err
+	}
+
+	return UnmarshalSavedQuery(b)
+}
+
+// CreateSavedQuery adds a custom query to a library.
+func (c *Client) CreateSavedQuery(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, sqr SavedQueryRequest) (SavedQuery, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries
+
+	if err := lib.validate(); err != nil {
+		return SavedQuery{}, err
+	}
+	if err := sqr.Validate(); err != nil {
+		return SavedQuery{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", string(lib)+"/queries", nil, sqr)
+	if err != nil {
+		return SavedQuery{}, err
+	}
+
+	return UnmarshalSavedQuery(b)
+}
+
+// UpdateSavedQuery changes a custom query.  Fields left empty in sqr are not changed.
+func (c *Client) UpdateSavedQuery(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, queryID string, sqr SavedQueryRequest) (SavedQuery, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries/{queryId}
+
+	return c.updateSavedQuery(ctx, tenant, lib, queryID, sqr, sqr)
+}
+
+// updateSavedQuery checks sqr and sends body as the update of a query.
+func (c *Client) updateSavedQuery(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, queryID string, sqr SavedQueryRequest, body interface{}) (SavedQuery, error) {
+
+	if err := lib.validate(); err != nil {
+		return SavedQuery{}, err
+	}
+	if _, err := uuid.Parse(queryID); err != nil {
+		return SavedQuery{}, fmt.Errorf("%s: %w", ErrQueryID, err)
+	}
+	if err := sqr.validateVariables(); err != nil {
+		return SavedQuery{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "PATCH", fmt.Sprintf("%s/queries/%s", lib, queryID), nil, body)
+	if err != nil {
+		return SavedQuery{}, err
+	}
+
+	return UnmarshalSavedQuery(b)
+}
+
+// DeleteSavedQuery removes a custom query from a library.
+func (c *Client) DeleteSavedQuery(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, queryID string) (DeletedResponse, error) {
+	// https://api-{dataRegion}.central.sophos.com/{live-discover|xdr-query}/v1/queries/{queryId}
+
+	if err := lib.validate(); err != nil {
+		return DeletedResponse{}, err
+	}
+	if _, err := uuid.Parse(queryID); err != nil {
+		return DeletedResponse{}, fmt.Errorf("%s: %w", ErrQueryID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "DELETE", fmt.Sprintf("%s/queries/%s", lib, queryID), nil, nil)
+	if err != nil {
+		return DeletedResponse{}, err
+	}
+
+	return UnmarshalDeletedResponse(b)
+}
+
+func (lib QueryLibrary) validate() error {
+	switch lib {
+	case LiveDiscoverLibrary, DataLakeLibrary:
+		return nil
+	default:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "QueryLibrary"}, Value: lib}
+	}
+}
+
+// Validate checks a query has a name and template, and that its variables have a name and
+// known type, are not repeated and are used in the template.
+func (sqr SavedQueryRequest) Validate() error {
+	if strings.TrimSpace(sqr.Name) == "" {
+		return ErrMissingInput{Argument: "Name"}
+	}
+	if strings.TrimSpace(sqr.Template) == "" {
+		return ErrMissingInput{Argument: "Template"}
+	}
+	return sqr.validateVariables()
+}
+
+func (sqr SavedQueryRequest) validateVariables() error {
+	seen := map[string]bool{}
+	for _, v := range sqr.Variables {
+		if v.Name == "" {
+			return ErrMissingInput{Argument: "Variables.Name"}
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%w: variable %s", ErrDuplicateItem, v.Name)
+		}
+		seen[v.Name] = true
+		if !v.DataType.valid() {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Variables.DataType"}, Value: v.DataType}
+		}
+		if sqr.Template != "" && !strings.Contains(sqr.Template, "$$"+v.Name+"$$") {
+			return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Variables.Name"}, Value: v.Name + " is not used in the template"}
+		}
+	}
+	return nil
+}
+
+func (t QueryVariableType) valid() bool {
+	switch t {
+	case VariableText, VariableInteger, VariableDouble, VariableBoolean, VariableDateTime, VariableSophosPID:
+		return true
+	default:
+		return false
+	}
+}
+
+func UnmarshalQueryCategories(data []byte) (QueryCategories, error) {
+	var r QueryCategories
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return QueryCategories{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalQueryCategory(data []byte) (QueryCategory, error) {
+	var r QueryCategory
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return QueryCategory{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalSavedQueries(data []byte) (SavedQueries, error) {
+	var r SavedQueries
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SavedQueries{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalSavedQuery(data []byte) (SavedQuery, error) {
+	var r SavedQuery
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SavedQuery{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+type QueryCategories struct {
+	Items []QueryCategory `json:"items"`
+	Pages Pages           `json:"pages"`
+}
+
+type QueryCategory struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	BuiltIn     bool   `json:"builtIn"`
+}
+
+type SavedQueries struct {
+	Items []SavedQuery `json:"items"`
+	Pages Pages        `json:"pages"`
+}
+
+type SavedQuery struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Code          string          `json:"code,omitempty"`
+	Categories    []QueryCategory `json:"categories,omitempty"`
+	Template      string          `json:"template"`
+	Variables     []QueryVariable `json:"variables,omitempty"`
+	Type          SavedQueryType  `json:"type,omitempty"`
+	SupportedOSes []Platform      `json:"supportedOSes,omitempty"`
+	CreatedAt     *time.Time      `json:"createdAt,omitempty"`
+	UpdatedAt     *time.Time      `json:"updatedAt,omitempty"`
+}
+
+// QueryVariable is a variable of a query template.  Value is only set when running a query.
+type QueryVariable struct {
+	Name        string            `json:"name"`
+	DataType    QueryVariableType `json:"dataType"`
+	Description string            `json:"description,omitempty"`
+	Value       string            `json:"value,omitempty"`
+}
+
+type QueryVariableType string
+
+const (
+	VariableText      QueryVariableType = "text"
+	VariableInteger   QueryVariableType = "integer"
+	VariableDouble    QueryVariableType = "double"
+	VariableBoolean   QueryVariableType = "boolean"
+	VariableDateTime  QueryVariableType = "dateTime"
+	VariableSophosPID QueryVariableType = "sophosPid"
+)
+
+type SavedQueryType string
+
+const (
+	SophosSavedQuery SavedQueryType = "sophos"
+	CustomSavedQuery SavedQueryType = "custom"
+)
+
+// SavedQueryRequest creates or updates a custom query.
+type SavedQueryRequest struct {
+	Name          string             `json:"name,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	Categories    []QueryCategoryRef `json:"categories,omitempty"`
+	Template      string             `json:"template,omitempty"`
+	Variables     []QueryVariable    `json:"variables,omitempty"`
+	SupportedOSes []Platform         `json:"supportedOSes,omitempty"`
+}
+
+type QueryCategoryRef struct {
+	ID string `json:"id"`
+}
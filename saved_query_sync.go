@@ -0,0 +1,341 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+
+Syncing a directory of query definitions to the saved queries of a tenant.
+
+Each query is kept in its own .json file holding a QueryDefinition.  The template can be left
+out of the json and kept in a .sql file of the same name next to it, which reads better in
+review.  Categories are given by name.
+
+PlanQuerySync compares the definitions with the custom queries of a tenant and works out what
+has to be created, updated and, when pruning, deleted.  ApplyQuerySync carries out a plan.
+Queries are matched by name, and Sophos' own queries are never changed.
+*/
+
+// QueryDefinition is a saved query as kept in a local directory.
+type QueryDefinition struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Categories    []string        `json:"categories,omitempty"`
+	Template      string          `json:"template,omitempty"`
+	Variables     []QueryVariable `json:"variables,omitempty"`
+	SupportedOSes []Platform      `json:"supportedOSes,omitempty"`
+	File          string          `json:"-"`
+}
+
+// LoadQueryDefinitions reads the query definitions in dir, sorted by name.  Two definitions
+// with the same name are an error.
+func LoadQueryDefinitions(dir string) ([]QueryDefinition, error) {
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []QueryDefinition
+	names := map[string]string{}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var def QueryDefinition
+		if err := json.Unmarshal(b, &def); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", ErrUnmarshalFailed, file, err)
+		}
+		def.File = file
+
+		if def.Template == "" {
+			sql, err := os.ReadFile(strings.TrimSuffix(file, ".json") + ".sql")
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			def.Template = string(sql)
+		}
+
+		if prev, ok := names[def.Name]; ok {
+			return nil, fmt.Errorf("%w: query %q is defined in %s and %s", ErrDuplicateItem, def.Name, prev, file)
+		}
+		names[def.Name] = file
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// PlanQuerySync works out the changes that make the custom queries in existing match defs.
+// Category names are looked up in categories.  Custom queries with no definition are deleted
+// only when prune is set.  Queries not typed custom, including those with no type, are left
+// alone.
+func PlanQuerySync(defs []QueryDefinition, existing SavedQueries, categories QueryCategories, prune bool) (QuerySyncPlan, error) {
+
+	categoryIDs := map[string]string{}
+	for _, qc := range categories.Items {
+		categoryIDs[qc.Name] = qc.ID
+	}
+
+	current := map[string]SavedQuery{}
+	for _, sq := range existing.Items {
+		if sq.Type == CustomSavedQuery {
+			current[sq.Name] = sq
+		}
+	}
+
+	var plan QuerySyncPlan
+	defined := map[string]bool{}
+	for _, def := range defs {
+		defined[def.Name] = true
+
+		sqr, err := def.request(categoryIDs)
+		if err != nil {
+			return QuerySyncPlan{}, err
+		}
+
+		sq, ok := current[def.Name]
+		if !ok {
+			plan.Actions = append(plan.Actions, QuerySyncAction{Op: QuerySyncCreate, Name: def.Name, Request: sqr})
+			continue
+		}
+
+		changes := savedQueryChanges(sq, sqr)
+		if len(changes) == 0 {
+			plan.Unchanged++
+			continue
+		}
+		plan.Actions = append(plan.Actions, QuerySyncAction{Op: QuerySyncUpdate, Name: def.Name, QueryID: sq.ID, Request: sqr, Changes: changes})
+	}
+
+	if prune {
+		for _, sq := range existing.Items {
+			if sq.Type != CustomSavedQuery || defined[sq.Name] {
+				continue
+			}
+			plan.Actions = append(plan.Actions, QuerySyncAction{Op: QuerySyncDelete, Name: sq.Name, QueryID: sq.ID})
+		}
+	}
+
+	order := map[QuerySyncOp]int{QuerySyncCreate: 0, QuerySyncUpdate: 1, QuerySyncDelete: 2}
+	sort.SliceStable(plan.Actions, func(i, j int) bool {
+		ai, aj := plan.Actions[i], plan.Actions[j]
+		if ai.Op != aj.Op {
+			return order[ai.Op] < order[aj.Op]
+		}
+		return ai.Name < aj.Name
+	})
+
+	return plan, nil
+}
+
+// ApplyQuerySync carries out the actions of a plan in order and returns the result of each.
+// A failed action does not stop the rest.
+func (c *Client) ApplyQuerySync(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, plan QuerySyncPlan) []QuerySyncResult {
+
+	results := make([]QuerySyncResult, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		var err error
+		switch action.Op {
+		case QuerySyncCreate:
+			_, err = c.CreateSavedQuery(ctx, tenant, lib, action.Request)
+		case QuerySyncUpdate:
+			_, err = c.updateSavedQuery(ctx, tenant, lib, action.QueryID, action.Request, action.Request.syncUpdate())
+		case QuerySyncDelete:
+			_, err = c.DeleteSavedQuery(ctx, tenant, lib, action.QueryID)
+		default:
+			err = ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Op"}, Value: action.Op}
+		}
+		results = append(results, QuerySyncResult{Action: action, Err: err})
+	}
+	return results
+}
+
+// SyncSavedQueries plans the sync of the definitions in dir with a tenant's library and,
+// unless DryRun is set, applies it.  The plan is returned either way so it can be shown.
+func (c *Client) SyncSavedQueries(ctx context.Context, tenant TenantsResponseItem, lib QueryLibrary, dir string, opts QuerySyncOptions) (QuerySyncPlan, []QuerySyncResult, error) {
+
+	defs, err := LoadQueryDefinitions(dir)
+	if err != nil {
+		return QuerySyncPlan{}, nil, err
+	}
+
+	existing, err := c.GetSavedQueries(ctx, tenant, lib, nil)
+	if err != nil {
+		return QuerySyncPlan{}, nil, err
+	}
+
+	categories, err := c.GetQueryCategories(ctx, tenant, lib)
+	if err != nil {
+		return QuerySyncPlan{}, nil, err
+	}
+
+	plan, err := PlanQuerySync(defs, existing, categories, opts.Prune)
+	if err != nil || opts.DryRun {
+		return plan, nil, err
+	}
+
+	return plan, c.ApplyQuerySync(ctx, tenant, lib, plan), nil
+}
+
+// String renders the plan one action a line, followed by a summary.
+func (p QuerySyncPlan) String() string {
+	var sb strings.Builder
+	counts := map[QuerySyncOp]int{}
+	for _, a := range p.Actions {
+		counts[a.Op]++
+		switch a.Op {
+		case QuerySyncCreate:
+			fmt.Fprintf(&sb, "+ create %q\n", a.Name)
+		case QuerySyncUpdate:
+			fmt.Fprintf(&sb, "~ update %q (%s)\n", a.Name, strings.Join(a.Changes, ", "))
+		case QuerySyncDelete:
+			fmt.Fprintf(&sb, "- delete %q\n", a.Name)
+		}
+	}
+	fmt.Fprintf(&sb, "Plan: %d to create, %d to update, %d to delete, %d unchanged.",
+		counts[QuerySyncCreate], counts[QuerySyncUpdate], counts[QuerySyncDelete], p.Unchanged)
+	return sb.String()
+}
+
+func (def QueryDefinition) request(categoryIDs map[string]string) (SavedQueryRequest, error) {
+	sqr := SavedQueryRequest{
+		Name:          def.Name,
+		Description:   def.Description,
+		Template:      strings.TrimSpace(def.Template),
+		Variables:     def.Variables,
+		SupportedOSes: def.SupportedOSes,
+	}
+	for _, name := range def.Categories {
+		id, ok := categoryIDs[name]
+		if !ok {
+			return SavedQueryRequest{}, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Categories"}, Value: fmt.Sprintf("%s in %s", name, def.File)}
+		}
+		sqr.Categories = append(sqr.Categories, QueryCategoryRef{ID: id})
+	}
+
+	if err := sqr.Validate(); err != nil {
+		return SavedQueryRequest{}, fmt.Errorf("query %q: %w", def.Name, err)
+	}
+	return sqr, nil
+}
+
+// syncUpdate is the body of a sync update.  Every field is sent, so a field cleared in the
+// definition is cleared in Central too.
+func (sqr SavedQueryRequest) syncUpdate() querySyncUpdate {
+	u := querySyncUpdate{
+		Name:          sqr.Name,
+		Description:   sqr.Description,
+		Categories:    sqr.Categories,
+		Template:      sqr.Template,
+		Variables:     sqr.Variables,
+		SupportedOSes: sqr.SupportedOSes,
+	}
+	if u.Categories == nil {
+		u.Categories = []QueryCategoryRef{}
+	}
+	if u.Variables == nil {
+		u.Variables = []QueryVariable{}
+	}
+	if u.SupportedOSes == nil {
+		u.SupportedOSes = []Platform{}
+	}
+	return u
+}
+
+// savedQueryChanges names the fields of sq that sqr changes.
+func savedQueryChanges(sq SavedQuery, sqr SavedQueryRequest) []string {
+	var changes []string
+
+	if sq.Description != sqr.Description {
+		changes = append(changes, "description")
+	}
+
+	have := map[string]bool{}
+	for _, qc := range sq.Categories {
+		have[qc.ID] = true
+	}
+	sameCategories := len(sq.Categories) == len(sqr.Categories)
+	for _, ref := range sqr.Categories {
+		sameCategories = sameCategories && have[ref.ID]
+	}
+	if !sameCategories {
+		changes = append(changes, "categories")
+	}
+
+	if strings.TrimSpace(sq.Template) != sqr.Template {
+		changes = append(changes, "template")
+	}
+
+	sameVariables := len(sq.Variables) == len(sqr.Variables)
+	for i := 0; sameVariables && i < len(sq.Variables); i++ {
+		a, b := sq.Variables[i], sqr.Variables[i]
+		sameVariables = a.Name == b.Name && a.DataType == b.DataType && a.Description == b.Description
+	}
+	if !sameVariables {
+		changes = append(changes, "variables")
+	}
+
+	sameOSes := len(sq.SupportedOSes) == len(sqr.SupportedOSes)
+	for i := 0; sameOSes && i < len(sq.SupportedOSes); i++ {
+		sameOSes = sq.SupportedOSes[i] == sqr.SupportedOSes[i]
+	}
+	if !sameOSes {
+		changes = append(changes, "supportedOSes")
+	}
+
+	return changes
+}
+
+// querySyncUpdate is SavedQueryRequest without omitempty.
+type querySyncUpdate struct {
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	Categories    []QueryCategoryRef `json:"categories"`
+	Template      string             `json:"template"`
+	Variables     []QueryVariable    `json:"variables"`
+	SupportedOSes []Platform         `json:"supportedOSes"`
+}
+
+type QuerySyncOptions struct {
+	Prune  bool
+	DryRun bool
+}
+
+type QuerySyncOp string
+
+const (
+	QuerySyncCreate QuerySyncOp = "create"
+	QuerySyncUpdate QuerySyncOp = "update"
+	QuerySyncDelete QuerySyncOp = "delete"
+)
+
+type QuerySyncPlan struct {
+	Actions   []QuerySyncAction
+	Unchanged int
+}
+
+// QuerySyncAction is one change of a plan.  QueryID is set for updates and deletes, Request
+// for creates and updates, and Changes names the fields an update changes.
+type QuerySyncAction struct {
+	Op      QuerySyncOp
+	Name    string
+	QueryID string
+	Request SavedQueryRequest
+	Changes []string
+}
+
+type QuerySyncResult struct {
+	Action QuerySyncAction
+	Err    error
+}
@@ -0,0 +1,94 @@
+package sophoscentral
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestPlanQuerySync(t *testing.T) {
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		a.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("listening_ports.json", `{"name": "Listening ports", "categories": ["Network"],
+		"variables": [{"name": "port", "dataType": "integer", "description": "port to look for"}]}`)
+	write("listening_ports.sql", "SELECT pid, port FROM listening_ports WHERE port = $$port$$\n")
+	write("new_query.json", `{"name": "New query", "template": "SELECT 1"}`)
+	write("unchanged.json", `{"name": "Unchanged", "description": "same", "template": "SELECT 2"}`)
+
+	defs, err := LoadQueryDefinitions(dir)
+	a.NoError(err)
+	a.Len(defs, 3)
+
+	categories := QueryCategories{Items: []QueryCategory{{ID: "c1", Name: "Network"}}}
+	existing := SavedQueries{Items: []SavedQuery{
+		{ID: "q1", Name: "Listening ports", Type: CustomSavedQuery, Categories: []QueryCategory{{ID: "c1", Name: "Network"}},
+			Template: "SELECT pid, port FROM listening_ports", Variables: []QueryVariable{{Name: "port", DataType: VariableInteger, Description: "port to look for"}}},
+		{ID: "q2", Name: "Unchanged", Type: CustomSavedQuery, Description: "same", Template: "SELECT 2\n"},
+		{ID: "q3", Name: "Retired", Type: CustomSavedQuery, Template: "SELECT 3"},
+		{ID: "q4", Name: "Sophos query", Type: SophosSavedQuery, Template: "SELECT 4"},
+		{ID: "q5", Name: "Untyped query", Template: "SELECT 5"},
+	}}
+
+	plan, err := PlanQuerySync(defs, existing, categories, false)
+	a.NoError(err)
+	a.Equal(1, plan.Unchanged)
+	a.Len(plan.Actions, 2)
+
+	plan, err = PlanQuerySync(defs, existing, categories, true)
+	a.NoError(err)
+	a.Equal(`+ create "New query"
+~ update "Listening ports" (template)
+- delete "Retired"
+Plan: 1 to create, 1 to update, 1 to delete, 1 unchanged.`, plan.String())
+	a.Equal("q1", plan.Actions[1].QueryID)
+	a.Equal([]QueryCategoryRef{{ID: "c1"}}, plan.Actions[1].Request.Categories)
+
+	_, err = PlanQuerySync(defs, existing, QueryCategories{}, false)
+	a.Error(err)
+}
+
+func TestClient_ApplyQuerySync_clears(t *testing.T) {
+	a := assert.New(t)
+
+	var sent string
+	hc := httpClientWithRequestRecorder(200, `{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "name": "Listening ports", "template": "SELECT 1"}`, func(req *http.Request, body []byte) {
+		a.Equal("PATCH", req.Method)
+		a.Equal("/live-discover/v1/queries/bc893b97-86a8-41aa-b65c-910e11505605", req.URL.Path)
+		sent = string(body)
+	})
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	existing := SavedQueries{Items: []SavedQuery{{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Name: "Listening ports", Type: CustomSavedQuery,
+		Description: "old", Categories: []QueryCategory{{ID: "c1", Name: "Network"}}, Template: "SELECT 1"}}}
+	defs := []QueryDefinition{{Name: "Listening ports", Template: "SELECT 1"}}
+
+	plan, err := PlanQuerySync(defs, existing, QueryCategories{}, false)
+	a.NoError(err)
+	a.Equal([]string{"description", "categories"}, plan.Actions[0].Changes)
+
+	results := c.ApplyQuerySync(context.Background(), tenant, LiveDiscoverLibrary, plan)
+	a.Len(results, 1)
+	a.NoError(results[0].Err)
+	a.JSONEq(`{"name": "Listening ports", "description": "", "categories": [], "template": "SELECT 1", "variables": [], "supportedOSes": []}`, sent)
+}
+
+func TestSavedQueryRequest_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(SavedQueryRequest{Name: "n", Template: "SELECT * FROM processes WHERE name = '$$name$$'",
+		Variables: []QueryVariable{{Name: "name", DataType: VariableText}}}.Validate())
+	a.Error(SavedQueryRequest{Name: "n", Template: "SELECT 1", Variables: []QueryVariable{{Name: "unused", DataType: VariableText}}}.Validate())
+	a.Error(SavedQueryRequest{Name: "n", Template: "SELECT $$v$$", Variables: []QueryVariable{{Name: "v", DataType: "blob"}}}.Validate())
+	a.Error(SavedQueryRequest{Template: "SELECT 1"}.Validate())
+}
@@ -1,11 +1,18 @@
 package sophoscentral
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 type ErrorResponse struct {
@@ -34,6 +41,21 @@ var ErrInvalidTenantID = errors.New("invalid tenant id")
 var ErrInvalidOrganizationID = errors.New("invalid organization id")
 var ErrInvalidPartnerID = errors.New("invalid partner id")
 var ErrAlertID = errors.New("invalid alert id")
+var ErrEndpointID = errors.New("invalid endpoint id")
+var ErrGroupID = errors.New("invalid group id")
+var ErrPolicyID = errors.New("invalid policy id")
+var ErrItemID = errors.New("invalid item id")
+var ErrInvalidSHA256 = errors.New("invalid sha256")
+var ErrDuplicateItem = errors.New("item already exists")
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+var ErrMigrationID = errors.New("invalid migration id")
+var ErrCategoryID = errors.New("invalid category id")
+var ErrQueryID = errors.New("invalid query id")
+var ErrRunID = errors.New("invalid run id")
+var ErrCaseID = errors.New("invalid case id")
+var ErrFirewallID = errors.New("invalid firewall id")
+var ErrMessageID = errors.New("invalid message id")
+var ErrMailboxID = errors.New("invalid mailbox id")
 var ErrUnmarshalFailed = errors.New("failed to unmarshal")
 var ErrMarshalFailed = errors.New("failed to marshal")
 var ErrFailedToCreateRequest = errors.New("failed to create new request")
@@ -43,6 +65,108 @@ var Err500Returned = errors.New("500 type status code returned")
 var Err400Returned = errors.New("400 type status code returned")
 var ErrInvalidQueryParams = errors.New("query params failed to verify")
 
+// tenantRequest is the request path shared by the tenant scoped calls.  It validates
+// the tenant, sets the tenant and auth headers, and makes the request against the
+// tenant's api host once the client's rate limit allows.  Anything other than a GET
+// changes state in Central, so those requests are logged to the client logger to leave
+// an audit trail of what was done.
+func (c *Client) tenantRequest(ctx context.Context, tenant TenantsResponseItem, method, path string, queryParams map[string]string, body interface{}) ([]byte, error) {
+
+	if ctx == nil {
+		ctx = c.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, err := uuid.Parse(tenant.ID); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrInvalidTenantID, err)
+	}
+
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrMarshalFailed, err)
+		}
+		payload = bytes.NewBuffer(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, tenant.ApiHost+path, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrFailedToCreateRequest, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", tenant.ID)
+	c.token.SetAuthHeader(req)
+
+	if queryParams != nil {
+		q := req.URL.Query()
+		for k, v := range queryParams {
+			q.Add(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	if method != http.MethodGet && c.logger != nil {
+		c.logger.WithFields(logrus.Fields{
+			"tenantID": tenant.ID,
+			"method":   method,
+			"path":     path,
+		}).Info("tenant request")
+	}
+
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	b, err := MakeRequest(c.httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrHttpDo, err)
+	}
+	return b, nil
+}
+
+// tenantPages walks every page of a tenant scoped list.  Each page is handed to page,
+// which unmarshals and keeps the items and returns the page details.  Key based paging
+// (nextKey) is followed when the api uses it, otherwise page numbers are used.
+func (c *Client) tenantPages(ctx context.Context, tenant TenantsResponseItem, path string, queryParams map[string]string, page func([]byte) (Pages, error)) error {
+
+	qp := map[string]string{"pageTotal": "true"}
+	for k, v := range queryParams {
+		qp[k] = v
+	}
+
+	for {
+		b, err := c.tenantRequest(ctx, tenant, http.MethodGet, path, qp, nil)
+		if err != nil {
+			return err
+		}
+
+		p, err := page(b)
+		if err != nil {
+			return err
+		}
+
+		if !p.next(qp) {
+			return nil
+		}
+	}
+}
+
+// next sets the query params for the page after p and reports whether there is one.
+func (p Pages) next(qp map[string]string) bool {
+	switch {
+	case p.NextKey != "":
+		qp["pageFromKey"] = p.NextKey
+	case p.Current > 0 && p.Current < p.Total:
+		qp["page"] = strconv.Itoa(p.Current + 1)
+	default:
+		return false
+	}
+	return true
+}
+
 func MakeRequest(hc *http.Client, req *http.Request)([]byte, error){
 
 	resp, err := hc.Do(req)
@@ -0,0 +1,313 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+
+Implementation for sophos central SIEM INTEGRATION API
+https://developer.sophos.com/docs/siem-v1/1/overview
+
+GET		/events
+GET		/alerts
+
+Both feeds are read with a cursor.  The first request gives from_date (no more than 24 hours
+ago), every response returns next_cursor, and has_more is set while there is more to read
+straight away.  The next poll carries on from the last next_cursor.
+*/
+
+// SIEM feed names, used with the tenant ID to key cursors in a SIEMCursorStore.
+const (
+	SIEMEventsFeed = "events"
+	SIEMAlertsFeed = "alerts"
+)
+
+// MaxSIEMLimit is the most items the SIEM api returns per page.
+const MaxSIEMLimit = 1000
+
+// GetSIEMEvents returns one page of SIEM events.
+func (c *Client) GetSIEMEvents(ctx context.Context, tenant TenantsResponseItem, q SIEMQuery) (SIEMEvents, error) {
+	// https://api-{dataRegion}.central.sophos.com/siem/v1/events
+
+	qp, err := q.queryParams()
+	if err != nil {
+		return SIEMEvents{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/siem/v1/events", qp, nil)
+	if err != nil {
+		return SIEMEvents{}, err
+	}
+
+	return UnmarshalSIEMEvents(b)
+}
+
+// GetSIEMAlerts returns one page of SIEM alerts.
+func (c *Client) GetSIEMAlerts(ctx context.Context, tenant TenantsResponseItem, q SIEMQuery) (SIEMAlerts, error) {
+	// https://api-{dataRegion}.central.sophos.com/siem/v1/alerts
+
+	qp, err := q.queryParams()
+	if err != nil {
+		return SIEMAlerts{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", "/siem/v1/alerts", qp, nil)
+	if err != nil {
+		return SIEMAlerts{}, err
+	}
+
+	return UnmarshalSIEMAlerts(b)
+}
+
+// EachSIEMEvent reads SIEM events until has_more is false, calling fn for each one.  When store
+// is not nil the read starts from the stored cursor of the tenant, if there is one, and the
+// cursor is saved after every page fn has handled without error.  A collector that is stopped
+// part way through a page reads that page again when restarted.
+func (c *Client) EachSIEMEvent(ctx context.Context, tenant TenantsResponseItem, q SIEMQuery, store SIEMCursorStore, fn func(SIEMEvent) error) error {
+	return c.siemPages(ctx, tenant, SIEMEventsFeed, q, store, func(q SIEMQuery) (SIEMCursor, error) {
+		events, err := c.GetSIEMEvents(ctx, tenant, q)
+		if err != nil {
+			return SIEMCursor{}, err
+		}
+		for _, e := range events.Items {
+			if err := fn(e); err != nil {
+				return SIEMCursor{}, err
+			}
+		}
+		return events.SIEMCursor, nil
+	})
+}
+
+// EachSIEMAlert reads SIEM alerts until has_more is false, calling fn for each one.  Cursors are
+// handled as in EachSIEMEvent.
+func (c *Client) EachSIEMAlert(ctx context.Context, tenant TenantsResponseItem, q SIEMQuery, store SIEMCursorStore, fn func(SIEMAlert) error) error {
+	return c.siemPages(ctx, tenant, SIEMAlertsFeed, q, store, func(q SIEMQuery) (SIEMCursor, error) {
+		alerts, err := c.GetSIEMAlerts(ctx, tenant, q)
+		if err != nil {
+			return SIEMCursor{}, err
+		}
+		for _, a := range alerts.Items {
+			if err := fn(a); err != nil {
+				return SIEMCursor{}, err
+			}
+		}
+		return alerts.SIEMCursor, nil
+	})
+}
+
+// EachPartnerSIEMEvent runs EachSIEMEvent for every tenant in turn.  A tenant that fails does not
+// stop the others; its error is returned keyed by tenant ID.  The run stops when ctx is done.
+func (c *Client) EachPartnerSIEMEvent(ctx context.Context, tenants []TenantsResponseItem, q SIEMQuery, store SIEMCursorStore, fn func(TenantsResponseItem, SIEMEvent) error) map[string]error {
+	return eachTenant(ctx, tenants, func(tenant TenantsResponseItem) error {
+		return c.EachSIEMEvent(ctx, tenant, q, store, func(e SIEMEvent) error {
+			return fn(tenant, e)
+		})
+	})
+}
+
+// EachPartnerSIEMAlert runs EachSIEMAlert for every tenant in turn, as in EachPartnerSIEMEvent.
+func (c *Client) EachPartnerSIEMAlert(ctx context.Context, tenants []TenantsResponseItem, q SIEMQuery, store SIEMCursorStore, fn func(TenantsResponseItem, SIEMAlert) error) map[string]error {
+	return eachTenant(ctx, tenants, func(tenant TenantsResponseItem) error {
+		return c.EachSIEMAlert(ctx, tenant, q, store, func(a SIEMAlert) error {
+			return fn(tenant, a)
+		})
+	})
+}
+
+func eachTenant(ctx context.Context, tenants []TenantsResponseItem, fn func(TenantsResponseItem) error) map[string]error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	errs := map[string]error{}
+	for _, tenant := range tenants {
+		if err := ctx.Err(); err != nil {
+			errs[tenant.ID] = err
+			continue
+		}
+		if err := fn(tenant); err != nil {
+			errs[tenant.ID] = err
+		}
+	}
+	return errs
+}
+
+// siemPages follows next_cursor until has_more is false.  page reads and handles one page.
+func (c *Client) siemPages(ctx context.Context, tenant TenantsResponseItem, feed string, q SIEMQuery, store SIEMCursorStore, page func(SIEMQuery) (SIEMCursor, error)) error {
+
+	key := SIEMCursorKey(tenant.ID, feed)
+	if store != nil && q.Cursor == "" {
+		cursor, err := store.LoadCursor(key)
+		if err != nil {
+			return err
+		}
+		q.Cursor = cursor
+	}
+
+	for {
+		sc, err := page(q)
+		if err != nil {
+			return err
+		}
+
+		if sc.NextCursor != "" {
+			q.Cursor = sc.NextCursor
+			if store != nil {
+				if err := store.SaveCursor(key, sc.NextCursor); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !sc.HasMore || sc.NextCursor == "" {
+			return nil
+		}
+	}
+}
+
+// SIEMCursorKey is the key a cursor for feed of tenantID is stored under.
+func SIEMCursorKey(tenantID, feed string) string {
+	return tenantID + "/" + feed
+}
+
+// queryParams builds the query for a SIEM request.  A cursor takes the place of from_date.
+func (q SIEMQuery) queryParams() (map[string]string, error) {
+	if q.Limit < 0 || q.Limit > MaxSIEMLimit {
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Limit"}, Value: q.Limit}
+	}
+
+	qp := map[string]string{}
+	if q.Limit > 0 {
+		qp["limit"] = strconv.Itoa(q.Limit)
+	}
+	switch {
+	case q.Cursor != "":
+		qp["cursor"] = q.Cursor
+	case !q.FromDate.IsZero():
+		qp["from_date"] = strconv.FormatInt(q.FromDate.Unix(), 10)
+	}
+	if len(q.ExcludeTypes) > 0 {
+		qp["exclude_types"] = strings.Join(q.ExcludeTypes, ",")
+	}
+	return qp, nil
+}
+
+func UnmarshalSIEMEvents(data []byte) (SIEMEvents, error) {
+	var r SIEMEvents
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SIEMEvents{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalSIEMAlerts(data []byte) (SIEMAlerts, error) {
+	var r SIEMAlerts
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return SIEMAlerts{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// SIEMQuery selects what a SIEM request reads.  FromDate is only used when there is no Cursor.
+type SIEMQuery struct {
+	Limit        int
+	Cursor       string
+	FromDate     time.Time
+	ExcludeTypes []string
+}
+
+type SIEMCursor struct {
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type SIEMEvents struct {
+	SIEMCursor
+	Items []SIEMEvent `json:"items"`
+}
+
+type SIEMAlerts struct {
+	SIEMCursor
+	Items []SIEMAlert `json:"items"`
+}
+
+type SIEMEvent struct {
+	ID              string               `json:"id"`
+	CustomerID      string               `json:"customer_id"`
+	Type            string               `json:"type"`
+	Name            string               `json:"name"`
+	Severity        Severity             `json:"severity"`
+	Group           string               `json:"group,omitempty"`
+	Location        string               `json:"location,omitempty"`
+	Source          string               `json:"source,omitempty"`
+	SourceInfo      *SIEMSourceInfo      `json:"source_info,omitempty"`
+	EndpointID      string               `json:"endpoint_id,omitempty"`
+	EndpointType    string               `json:"endpoint_type,omitempty"`
+	UserID          string               `json:"user_id,omitempty"`
+	Origin          string               `json:"origin,omitempty"`
+	Threat          string               `json:"threat,omitempty"`
+	AppSHA256       string               `json:"appSha256,omitempty"`
+	AppCerts        []SIEMAppCert        `json:"appCerts,omitempty"`
+	CoreRemedyItems *SIEMCoreRemedyItems `json:"core_remedy_items,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	When            time.Time            `json:"when"`
+}
+
+type SIEMAlert struct {
+	ID                  string        `json:"id"`
+	CustomerID          string        `json:"customer_id"`
+	Type                string        `json:"type"`
+	Description         string        `json:"description"`
+	Severity            Severity      `json:"severity"`
+	Location            string        `json:"location,omitempty"`
+	Source              string        `json:"source,omitempty"`
+	Threat              string        `json:"threat,omitempty"`
+	ThreatCleanable     bool          `json:"threat_cleanable"`
+	EventServiceEventID string        `json:"event_service_event_id,omitempty"`
+	Data                SIEMAlertData `json:"data"`
+	CreatedAt           time.Time     `json:"created_at"`
+	When                time.Time     `json:"when"`
+}
+
+type SIEMAlertData struct {
+	EndpointID       string               `json:"endpoint_id,omitempty"`
+	EndpointJavaID   string               `json:"endpoint_java_id,omitempty"`
+	EndpointPlatform string               `json:"endpoint_platform,omitempty"`
+	EndpointType     string               `json:"endpoint_type,omitempty"`
+	EventServiceID   string               `json:"event_service_id,omitempty"`
+	ThreatID         string               `json:"threat_id,omitempty"`
+	ThreatStatus     string               `json:"threat_status,omitempty"`
+	UserMatchUUID    string               `json:"user_match_uuid,omitempty"`
+	SourceInfo       *SIEMSourceInfo      `json:"source_info,omitempty"`
+	CoreRemedyItems  *SIEMCoreRemedyItems `json:"core_remedy_items,omitempty"`
+	InsertedAt       int64                `json:"inserted_at,omitempty"`
+}
+
+type SIEMSourceInfo struct {
+	IP string `json:"ip"`
+}
+
+type SIEMAppCert struct {
+	Signer     string `json:"signer"`
+	Thumbprint string `json:"thumbprint"`
+}
+
+type SIEMCoreRemedyItems struct {
+	Items      []SIEMCoreRemedyItem `json:"items"`
+	TotalItems int                  `json:"totalItems"`
+}
+
+type SIEMCoreRemedyItem struct {
+	Type       string `json:"type"`
+	Descriptor string `json:"descriptor"`
+	Result     string `json:"result"`
+	SophosPID  string `json:"sophosPid,omitempty"`
+}
@@ -0,0 +1,118 @@
+package sophoscentral
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SIEMCursorStore keeps the last SIEM cursor read for each tenant and feed, so a collector
+// that is restarted carries on where it left off.  Keys are made with SIEMCursorKey.
+// LoadCursor returns "" with no error when nothing is stored for key.
+type SIEMCursorStore interface {
+	LoadCursor(key string) (string, error)
+	SaveCursor(key, cursor string) error
+}
+
+// MemoryCursorStore keeps cursors in memory.  It is safe for concurrent use.
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]string
+}
+
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: map[string]string{}}
+}
+
+func (s *MemoryCursorStore) LoadCursor(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[key], nil
+}
+
+func (s *MemoryCursorStore) SaveCursor(key, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cursors == nil {
+		s.cursors = map[string]string{}
+	}
+	s.cursors[key] = cursor
+	return nil
+}
+
+// FileCursorStore keeps cursors in a JSON file.  Every save rewrites the file through a
+// temporary file and a rename, so a crash mid save leaves the previous cursors in place.
+// It is safe for concurrent use within one process.
+type FileCursorStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileCursorStore(path string) (*FileCursorStore, error) {
+	if path == "" {
+		return nil, ErrMissingInput{Argument: "path"}
+	}
+	return &FileCursorStore{path: path}, nil
+}
+
+func (s *FileCursorStore) LoadCursor(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursors, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return cursors[key], nil
+}
+
+func (s *FileCursorStore) SaveCursor(key, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursors, err := s.read()
+	if err != nil {
+		return err
+	}
+	cursors[key] = cursor
+
+	b, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrMarshalFailed, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileCursorStore) read() (map[string]string, error) {
+	cursors := map[string]string{}
+
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &cursors); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return cursors, nil
+}
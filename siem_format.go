@@ -0,0 +1,471 @@
+package sophoscentral
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+
+Formatters for sending SIEM events, alerts and endpoints on to a SIEM.
+
+Everything to be sent is first turned into a Record, a flat list of fields named after the
+Sophos json they came from ("source_info.ip", "managedAgent.id", ...).  A Formatter then
+renders a Record as one line of JSON, CEF, LEEF or key=value pairs.  The output names of the
+fields are set with a FieldMapping.
+*/
+
+// recordTimeLayout is how times are written in records, millisecond precision as Central uses.
+const recordTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// leefTimeFormat describes recordTimeLayout to LEEF consumers in devTimeFormat.
+const leefTimeFormat = "yyyy-MM-dd'T'HH:mm:ss.SSSXXX"
+
+// DefaultCEFMapping is the mapping Sophos' own SIEM integration uses to build CEF extensions,
+// with the matching fields of alerts and endpoints from the common and endpoint apis added.
+var DefaultCEFMapping = FieldMapping{
+	"source":         "suser",
+	"when":           "end",
+	"user_id":        "duid",
+	"created_at":     "rt",
+	"full_file_path": "filePath",
+	"location":       "dhost",
+
+	"raisedAt":       "rt",
+	"hostname":       "dhost",
+	"source_info.ip": "src",
+	"lastSeenAt":     "end",
+}
+
+// DefaultLEEFMapping renames fields to the predefined LEEF attributes.  Other fields keep
+// their Sophos names.
+var DefaultLEEFMapping = FieldMapping{
+	"created_at":     "devTime",
+	"raisedAt":       "devTime",
+	"source":         "usrName",
+	"location":       "identHostName",
+	"hostname":       "identHostName",
+	"source_info.ip": "src",
+}
+
+// Formatter renders a record as a single line, without a line ending.
+type Formatter interface {
+	Format(r Record) ([]byte, error)
+}
+
+// FieldMapping maps the Sophos name of a field to the name it is written with.  A field mapped
+// to "" is left out.
+type FieldMapping map[string]string
+
+// Record is a SIEM event, alert or endpoint ready to be formatted.  Fields hold every value
+// that is set, in a fixed order; the other members are used for format headers.
+type Record struct {
+	Kind     string
+	ID       string
+	Type     string
+	Name     string
+	Severity Severity
+	Time     time.Time
+	Fields   []Field
+	Source   interface{}
+}
+
+type Field struct {
+	Key   string
+	Value string
+}
+
+// WriteRecords formats each record with f and writes it to w followed by a newline.
+func WriteRecords(w io.Writer, f Formatter, records ...Record) error {
+	for _, r := range records {
+		b, err := f.Format(r)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONLinesFormatter writes records as JSON.  With no Mapping the value the record was made
+// from is written unchanged, otherwise the mapped fields are written as one flat object.
+type JSONLinesFormatter struct {
+	Mapping FieldMapping
+}
+
+func (f JSONLinesFormatter) Format(r Record) ([]byte, error) {
+	var v interface{} = r.Source
+	if f.Mapping != nil || r.Source == nil {
+		obj := map[string]string{}
+		for _, fld := range f.Mapping.apply(r.Fields, false) {
+			obj[fld.Key] = fld.Value
+		}
+		v = obj
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMarshalFailed, err)
+	}
+	return b, nil
+}
+
+// CEFFormatter writes records in ArcSight Common Event Format.  The record type is the
+// signature ID and only mapped fields are written as extensions, as CEF only allows its
+// own extension keys.  A nil Mapping uses DefaultCEFMapping.
+type CEFFormatter struct {
+	Vendor  string
+	Product string
+	Version string
+	Mapping FieldMapping
+}
+
+// NewCEFFormatter returns a CEFFormatter with the device details Sophos uses.
+func NewCEFFormatter() CEFFormatter {
+	return CEFFormatter{Vendor: "sophos", Product: "sophos central", Version: "1.0"}
+}
+
+func (f CEFFormatter) Format(r Record) ([]byte, error) {
+	mapping := f.Mapping
+	if mapping == nil {
+		mapping = DefaultCEFMapping
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CEF:0")
+	for _, h := range []string{f.Vendor, f.Product, f.Version, r.Type, r.Name, cefSeverity(r.Severity)} {
+		sb.WriteByte('|')
+		sb.WriteString(cefHeaderEscaper.Replace(h))
+	}
+	sb.WriteByte('|')
+
+	for i, fld := range mapping.apply(r.Fields, true) {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(fld.Key)
+		sb.WriteByte('=')
+		sb.WriteString(cefExtensionEscaper.Replace(fld.Value))
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// LEEFFormatter writes records in IBM QRadar LEEF 2.0.  The record type is the event ID and is
+// also written as cat, with the severity as sev.  Attributes are separated by Delimiter, a tab
+// when not set.  A nil Mapping uses DefaultLEEFMapping.  LEEF has no escape for a backslash, so
+// only the delimiter and line breaks are escaped.  Backslashes are written as they are, except
+// that a run of them ending the value or coming before a delimiter or line break is doubled so
+// it is not read as escaping what follows.
+type LEEFFormatter struct {
+	Vendor    string
+	Product   string
+	Version   string
+	Delimiter rune
+	Mapping   FieldMapping
+}
+
+// NewLEEFFormatter returns a LEEFFormatter with the Sophos device details.
+func NewLEEFFormatter() LEEFFormatter {
+	return LEEFFormatter{Vendor: "Sophos", Product: "Central", Version: "1.0", Delimiter: '\t'}
+}
+
+func (f LEEFFormatter) Format(r Record) ([]byte, error) {
+	mapping := f.Mapping
+	if mapping == nil {
+		mapping = DefaultLEEFMapping
+	}
+	delim := f.Delimiter
+	if delim == 0 {
+		delim = '\t'
+	}
+	if delim == '=' || delim == '\\' || delim == '\n' || delim == '\r' {
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Delimiter"}, Value: string(delim)}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("LEEF:2.0")
+	for _, h := range []string{f.Vendor, f.Product, f.Version, r.Type} {
+		sb.WriteByte('|')
+		sb.WriteString(leefHeaderEscaper.Replace(h))
+	}
+	sb.WriteByte('|')
+	if delim < ' ' || delim > '~' || delim == '|' {
+		sb.WriteString(fmt.Sprintf("x%02X", delim))
+	} else {
+		sb.WriteRune(delim)
+	}
+	sb.WriteByte('|')
+
+	fields := []Field{{Key: "cat", Value: r.Type}}
+	if sev, ok := severityScores[r.Severity]; ok && sev > 0 {
+		fields = append(fields, Field{Key: "sev", Value: strconv.Itoa(sev)})
+	}
+	fields = append(fields, mapping.apply(r.Fields, false)...)
+	for _, fld := range fields {
+		if fld.Key == "devTime" {
+			fields = append(fields, Field{Key: "devTimeFormat", Value: leefTimeFormat})
+			break
+		}
+	}
+
+	for i, fld := range fields {
+		if i > 0 {
+			sb.WriteRune(delim)
+		}
+		sb.WriteString(fld.Key)
+		sb.WriteByte('=')
+		sb.WriteString(leefEscape(fld.Value, delim))
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// leefEscape escapes the delimiter and line breaks in a LEEF attribute value, doubling any
+// backslashes that would otherwise run into an escape.
+func leefEscape(v string, delim rune) string {
+	var sb strings.Builder
+	backslashes := 0
+	flush := func(double bool) {
+		n := backslashes
+		if double {
+			n *= 2
+		}
+		sb.WriteString(strings.Repeat(`\`, n))
+		backslashes = 0
+	}
+
+	for i, r := range v {
+		switch {
+		case r == '\\':
+			backslashes++
+		case r == delim:
+			flush(true)
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\r' && strings.HasPrefix(v[i:], "\r\n"):
+			flush(true)
+		case r == '\n':
+			flush(true)
+			sb.WriteString(`\n`)
+		case r == '\r':
+			flush(true)
+			sb.WriteString(`\r`)
+		default:
+			flush(false)
+			sb.WriteRune(r)
+		}
+	}
+	flush(true)
+
+	return sb.String()
+}
+
+// KeyValueFormatter writes records as space separated key="value" pairs, with backslashes,
+// quotes and line breaks escaped.  A nil Mapping keeps the Sophos field names.
+type KeyValueFormatter struct {
+	Mapping FieldMapping
+}
+
+func (f KeyValueFormatter) Format(r Record) ([]byte, error) {
+	var sb strings.Builder
+	for i, fld := range f.Mapping.apply(r.Fields, false) {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(fld.Key)
+		sb.WriteString(`="`)
+		sb.WriteString(keyValueEscaper.Replace(fld.Value))
+		sb.WriteByte('"')
+	}
+	return []byte(sb.String()), nil
+}
+
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+var cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+var leefHeaderEscaper = strings.NewReplacer(`|`, `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+var keyValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+
+// severityScores are the 0-10 CEF severities Sophos gives its severities.
+var severityScores = map[Severity]int{
+	"none":      0,
+	Low:         1,
+	Medium:      5,
+	High:        8,
+	"very_high": 10,
+	"critical":  10,
+}
+
+func cefSeverity(s Severity) string {
+	if sev, ok := severityScores[s]; ok {
+		return strconv.Itoa(sev)
+	}
+	return "Unknown"
+}
+
+// apply renames fields with the mapping.  Unmapped fields are kept under their own name
+// unless onlyMapped is set.
+func (m FieldMapping) apply(fields []Field, onlyMapped bool) []Field {
+	out := make([]Field, 0, len(fields))
+	for _, fld := range fields {
+		key, ok := m[fld.Key]
+		switch {
+		case ok && key == "":
+			continue
+		case ok:
+			fld.Key = key
+		case onlyMapped:
+			continue
+		}
+		out = append(out, fld)
+	}
+	return out
+}
+
+// SIEMEventRecord makes a record of a SIEM event.  As in Sophos' own integration the path of
+// the first item cleaned up is added as full_file_path.
+func SIEMEventRecord(e SIEMEvent) Record {
+	var fb fieldBuilder
+	fb.add("id", e.ID)
+	fb.add("type", e.Type)
+	fb.add("name", e.Name)
+	fb.add("severity", string(e.Severity))
+	fb.add("customer_id", e.CustomerID)
+	fb.add("group", e.Group)
+	fb.add("location", e.Location)
+	fb.add("source", e.Source)
+	if e.SourceInfo != nil {
+		fb.add("source_info.ip", e.SourceInfo.IP)
+	}
+	fb.add("endpoint_id", e.EndpointID)
+	fb.add("endpoint_type", e.EndpointType)
+	fb.add("user_id", e.UserID)
+	fb.add("origin", e.Origin)
+	fb.add("threat", e.Threat)
+	fb.add("appSha256", e.AppSHA256)
+	if e.CoreRemedyItems != nil && len(e.CoreRemedyItems.Items) > 0 {
+		fb.add("full_file_path", e.CoreRemedyItems.Items[0].Descriptor)
+	}
+	fb.addTime("created_at", e.CreatedAt)
+	fb.addTime("when", e.When)
+
+	return Record{Kind: SIEMEventsFeed, ID: e.ID, Type: e.Type, Name: e.Name, Severity: e.Severity, Time: e.CreatedAt, Fields: fb.fields, Source: e}
+}
+
+// SIEMAlertRecord makes a record of a SIEM alert.
+func SIEMAlertRecord(a SIEMAlert) Record {
+	var fb fieldBuilder
+	fb.add("id", a.ID)
+	fb.add("type", a.Type)
+	fb.add("description", a.Description)
+	fb.add("severity", string(a.Severity))
+	fb.add("customer_id", a.CustomerID)
+	fb.add("location", a.Location)
+	fb.add("source", a.Source)
+	if a.Data.SourceInfo != nil {
+		fb.add("source_info.ip", a.Data.SourceInfo.IP)
+	}
+	fb.add("endpoint_id", a.Data.EndpointID)
+	fb.add("endpoint_type", a.Data.EndpointType)
+	fb.add("threat", a.Threat)
+	fb.add("threat_status", a.Data.ThreatStatus)
+	fb.addTime("created_at", a.CreatedAt)
+	fb.addTime("when", a.When)
+
+	return Record{Kind: SIEMAlertsFeed, ID: a.ID, Type: a.Type, Name: a.Description, Severity: a.Severity, Time: a.CreatedAt, Fields: fb.fields, Source: a}
+}
+
+// AlertItemRecord makes a record of an alert from the common api.
+func AlertItemRecord(a AlertItem) Record {
+	var fb fieldBuilder
+	fb.add("id", a.ID)
+	fb.add("type", string(a.Type))
+	fb.add("description", a.Description)
+	fb.add("severity", string(a.Severity))
+	fb.add("category", string(a.Category))
+	fb.add("product", string(a.Product))
+	fb.add("groupKey", a.GroupKey)
+	if a.ManagedAgent.ID != nil {
+		fb.add("managedAgent.id", *a.ManagedAgent.ID)
+	}
+	if a.ManagedAgent.Type != nil {
+		fb.add("managedAgent.type", string(*a.ManagedAgent.Type))
+	}
+	fb.add("tenant.id", a.Tenant.ID)
+	fb.add("tenant.name", string(a.Tenant.Name))
+	fb.add("raisedAt", a.RaisedAt)
+
+	raised, _ := time.Parse(time.RFC3339, a.RaisedAt)
+	return Record{Kind: "alert", ID: a.ID, Type: string(a.Type), Name: a.Description, Severity: a.Severity, Time: raised, Fields: fb.fields, Source: a}
+}
+
+// EndpointRecords makes a record of each endpoint.
+func EndpointRecords(eps Endpoints) []Record {
+	records := make([]Record, 0, len(eps.Item))
+	for _, ep := range eps.Item {
+		records = append(records, EndpointItemRecord(ep))
+	}
+	return records
+}
+
+// EndpointItemRecord makes a record of an endpoint.  Its severity follows its overall health.
+func EndpointItemRecord(ep EndpointItem) Record {
+	var fb fieldBuilder
+	fb.add("id", ep.ID)
+	fb.add("type", string(ep.Type))
+	fb.add("hostname", ep.Hostname)
+	fb.add("tenant.id", ep.Tenant.ID)
+	fb.add("os.platform", string(ep.OS.Platform))
+	fb.add("os.name", ep.OS.Name)
+	fb.add("ipv4Addresses", strings.Join(ep.Ipv4Addresses, ","))
+	fb.add("ipv6Addresses", strings.Join(ep.Ipv6Addresses, ","))
+	fb.add("macAddresses", strings.Join(ep.MACAddresses, ","))
+	fb.add("group.name", ep.Group.Name)
+	if ep.AssociatedPerson != nil {
+		fb.add("associatedPerson.viaLogin", ep.AssociatedPerson.ViaLogin)
+	}
+	var sev Severity
+	if ep.Health != nil {
+		fb.add("health.overall", string(ep.Health.Overall))
+		fb.add("health.threats.status", string(ep.Health.Threats.Status))
+		fb.add("health.services.status", string(ep.Health.Services.Status))
+		sev = healthSeverity[ep.Health.Overall]
+	}
+	if ep.TamperProtectionEnabled != nil {
+		fb.add("tamperProtectionEnabled", strconv.FormatBool(*ep.TamperProtectionEnabled))
+	}
+	fb.add("lastSeenAt", ep.LastSeenAt)
+
+	lastSeen, _ := time.Parse(time.RFC3339, ep.LastSeenAt)
+	return Record{Kind: "endpoint", ID: ep.ID, Type: string(ep.Type), Name: ep.Hostname, Severity: sev, Time: lastSeen, Fields: fb.fields, Source: ep}
+}
+
+var healthSeverity = map[Overall]Severity{
+	Good:       Low,
+	Suspicious: Medium,
+	Bad:        High,
+}
+
+type fieldBuilder struct {
+	fields []Field
+}
+
+func (fb *fieldBuilder) add(key, value string) {
+	if value == "" {
+		return
+	}
+	fb.fields = append(fb.fields, Field{Key: key, Value: value})
+}
+
+func (fb *fieldBuilder) addTime(key string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	fb.add(key, t.Format(recordTimeLayout))
+}
@@ -0,0 +1,106 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSIEMEvent() SIEMEvent {
+	return SIEMEvent{
+		ID:         "e1",
+		Type:       "Event::Endpoint::Threat::Detected",
+		Name:       "Malware detected: 'Mal/a|b=c'",
+		Severity:   High,
+		Location:   "WIN10-01",
+		Source:     `CORP\jsmith`,
+		SourceInfo: &SIEMSourceInfo{IP: "10.0.0.5"},
+		CoreRemedyItems: &SIEMCoreRemedyItems{Items: []SIEMCoreRemedyItem{
+			{Type: "file", Descriptor: `C:\Users\jsmith\a=b.exe`},
+		}},
+		CreatedAt: mustParseTime("2021-05-05T11:47:30.148Z", time.RFC3339),
+	}
+}
+
+func TestFormatters(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		record    Record
+		want      string
+	}{
+		{
+			name:      "cef sophos mapping",
+			formatter: NewCEFFormatter(),
+			record:    SIEMEventRecord(testSIEMEvent()),
+			want: `CEF:0|sophos|sophos central|1.0|Event::Endpoint::Threat::Detected|Malware detected: 'Mal/a\|b=c'|8|` +
+				`dhost=WIN10-01 suser=CORP\\jsmith src=10.0.0.5 filePath=C:\\Users\\jsmith\\a\=b.exe rt=2021-05-05T11:47:30.148Z`,
+		},
+		{
+			name:      "cef custom mapping",
+			formatter: CEFFormatter{Vendor: "sophos", Product: "sophos central", Version: "1.0", Mapping: FieldMapping{"name": "msg"}},
+			record:    Record{Type: "t", Name: "n", Severity: "odd", Fields: []Field{{Key: "name", Value: "line one\nline two"}}},
+			want:      `CEF:0|sophos|sophos central|1.0|t|n|Unknown|msg=line one\nline two`,
+		},
+		{
+			name:      "leef",
+			formatter: NewLEEFFormatter(),
+			record:    SIEMEventRecord(testSIEMEvent()),
+			want: "LEEF:2.0|Sophos|Central|1.0|Event::Endpoint::Threat::Detected|x09|cat=Event::Endpoint::Threat::Detected\tsev=8\t" +
+				"id=e1\ttype=Event::Endpoint::Threat::Detected\tname=Malware detected: 'Mal/a|b=c'\tseverity=high\tidentHostName=WIN10-01\t" +
+				`usrName=CORP\jsmith` + "\tsrc=10.0.0.5\t" + `full_file_path=C:\Users\jsmith\a=b.exe` + "\tdevTime=2021-05-05T11:47:30.148Z\tdevTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSXXX",
+		},
+		{
+			name:      "leef caret delimiter",
+			formatter: LEEFFormatter{Vendor: "Sophos", Product: "Central", Version: "1.0", Delimiter: '^', Mapping: FieldMapping{"type": ""}},
+			record:    Record{Type: `t\u`, Fields: []Field{{Key: "type", Value: "t"}, {Key: "name", Value: "a^b\nc\\d"}}},
+			want:      `LEEF:2.0|Sophos|Central|1.0|t\u|^|cat=t\u^name=a\^b\nc\d`,
+		},
+		{
+			name:      "leef trailing backslash",
+			formatter: LEEFFormatter{Vendor: "Sophos", Product: "Central", Version: "1.0", Mapping: FieldMapping{"type": ""}},
+			record: Record{Type: "t", Fields: []Field{{Key: "filePath", Value: `C:\Users\`}, {Key: "next", Value: "a\\\tb\\"},
+				{Key: "last", Value: `x\`}}},
+			want: "LEEF:2.0|Sophos|Central|1.0|t|x09|cat=t\t" + `filePath=C:\Users\\` + "\t" + `next=a\\\` + "\t" + `b\\` + "\t" + `last=x\\`,
+		},
+		{
+			name:      "key value",
+			formatter: KeyValueFormatter{Mapping: FieldMapping{"source_info.ip": "src_ip"}},
+			record:    Record{Fields: []Field{{Key: "name", Value: `say "hi"`}, {Key: "source_info.ip", Value: "10.0.0.5"}}},
+			want:      `name="say \"hi\"" src_ip="10.0.0.5"`,
+		},
+		{
+			name:      "json lines mapped",
+			formatter: JSONLinesFormatter{Mapping: FieldMapping{"hostname": "host", "id": ""}},
+			record:    EndpointItemRecord(EndpointItem{ID: "bc893b97-86a8-41aa-b65c-910e11505605", Hostname: "srv01", Health: &Health{Overall: Bad}}),
+			want:      `{"health.overall":"bad","host":"srv01"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := tt.formatter.Format(tt.record)
+			a.NoError(err)
+			a.Equal(tt.want, string(got))
+		})
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	a := assert.New(t)
+
+	alert := AlertItem{ID: "a1", Type: XGFirewall, Severity: Medium, Description: "Firewall down", RaisedAt: "2021-05-05T11:47:30.148Z"}
+
+	var buf bytes.Buffer
+	a.NoError(WriteRecords(&buf, JSONLinesFormatter{}, AlertItemRecord(alert), SIEMEventRecord(SIEMEvent{ID: "e1"})))
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	a.Len(lines, 2)
+
+	got, err := UnmarshalAlertItem(lines[0])
+	a.NoError(err)
+	a.Equal(alert, got)
+}
@@ -0,0 +1,101 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_EachSIEMEvent(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	pages := map[string]string{
+		"":   `{"has_more": true, "next_cursor": "c1", "items": [{"id": "e1", "type": "Event::Endpoint::Threat::Detected", "severity": "high", "created_at": "2021-05-05T11:47:30.148Z"}]}`,
+		"c1": `{"has_more": false, "next_cursor": "c2", "items": [{"id": "e2", "type": "Event::Endpoint::UpdateSuccess", "severity": "low"}]}`,
+		"c2": `{"has_more": false, "next_cursor": "c2", "items": []}`,
+	}
+
+	var queries []string
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			queries = append(queries, req.URL.RawQuery)
+			body := pages[req.URL.Query().Get("cursor")]
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	store, err := NewFileCursorStore(filepath.Join(t.TempDir(), "cursors.json"))
+	a.NoError(err)
+
+	from := mustParseTime("2021-05-05T00:00:00Z", time.RFC3339)
+	q := SIEMQuery{Limit: 200, FromDate: from, ExcludeTypes: []string{"Event::Endpoint::UpdateSuccess", "Event::Endpoint::Registered"}}
+
+	var ids []string
+	err = c.EachSIEMEvent(context.Background(), tenant, q, store, func(e SIEMEvent) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+	a.NoError(err)
+	a.Equal([]string{"e1", "e2"}, ids)
+	a.Equal([]string{
+		"exclude_types=Event%3A%3AEndpoint%3A%3AUpdateSuccess%2CEvent%3A%3AEndpoint%3A%3ARegistered&from_date=1620172800&limit=200",
+		"cursor=c1&exclude_types=Event%3A%3AEndpoint%3A%3AUpdateSuccess%2CEvent%3A%3AEndpoint%3A%3ARegistered&limit=200",
+	}, queries)
+
+	// a restarted collector resumes from the stored cursor rather than from_date
+	store, err = NewFileCursorStore(store.path)
+	a.NoError(err)
+	cursor, err := store.LoadCursor(SIEMCursorKey(tenant.ID, SIEMEventsFeed))
+	a.NoError(err)
+	a.Equal("c2", cursor)
+
+	queries = nil
+	err = c.EachSIEMEvent(context.Background(), tenant, SIEMQuery{FromDate: from}, store, func(e SIEMEvent) error {
+		return errors.New("no events expected")
+	})
+	a.NoError(err)
+	a.Equal([]string{"cursor=c2"}, queries)
+}
+
+func TestClient_EachSIEMAlert_keepsCursorOnError(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: httpClientWithRoundTripper(200,
+		`{"has_more": false, "next_cursor": "c1", "items": [{"id": "a1", "description": "Malware detected", "data": {"endpoint_id": "bc893b97-86a8-41aa-b65c-910e11505605"}}]}`)}
+
+	store := NewMemoryCursorStore()
+	a.NoError(store.SaveCursor(SIEMCursorKey(tenant.ID, SIEMAlertsFeed), "c0"))
+
+	errs := c.EachPartnerSIEMAlert(context.Background(), []TenantsResponseItem{tenant}, SIEMQuery{}, store, func(tr TenantsResponseItem, sa SIEMAlert) error {
+		a.Equal("bc893b97-86a8-41aa-b65c-910e11505605", sa.Data.EndpointID)
+		return errors.New("sink down")
+	})
+	a.Len(errs, 1)
+	a.Error(errs[tenant.ID])
+
+	cursor, _ := store.LoadCursor(SIEMCursorKey(tenant.ID, SIEMAlertsFeed))
+	a.Equal("c0", cursor)
+}
+
+func TestSIEMQuery_queryParams(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := SIEMQuery{Limit: MaxSIEMLimit + 1}.queryParams()
+	a.Error(err)
+
+	qp, err := SIEMQuery{Cursor: "c1", FromDate: time.Now()}.queryParams()
+	a.NoError(err)
+	a.Equal(map[string]string{"cursor": "c1"}, qp)
+}
@@ -0,0 +1,550 @@
+package sophoscentral
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+
+Syslog forwarding of Central alerts and SIEM events.
+
+A SyslogForwarder holds a buffer of messages and a connection to a syslog collector.  Send
+adds a record to the buffer and blocks while the buffer is full, which holds back whatever is
+reading from Central until the collector takes messages again.  Run writes the buffer to the
+collector, reconnecting whenever the connection fails.  A message the collector keeps refusing
+is dropped after a few tries, and messages still buffered when Run stops are dropped too; both
+are reported to SyslogConfig.OnError.
+
+ForwardAlerts and ForwardSIEMEvents poll a tenant and send what they read to a forwarder.
+*/
+
+// Syslog defaults used when a SyslogConfig leaves them unset.
+const (
+	DefaultSyslogBufferSize     = 1000
+	DefaultSyslogDialTimeout    = 10 * time.Second
+	DefaultSyslogReconnectDelay = 5 * time.Second
+	DefaultSyslogMaxBackoff     = time.Minute
+	DefaultSyslogMaxRetries     = 3
+	DefaultSyslogAppName        = "sophos-central"
+	DefaultSyslogFacility       = 16 // local0
+	DefaultForwardPollInterval  = time.Minute
+)
+
+type SyslogFormat string
+
+const (
+	RFC5424 SyslogFormat = "rfc5424"
+	RFC3164 SyslogFormat = "rfc3164"
+)
+
+type SyslogNetwork string
+
+const (
+	SyslogUDP SyslogNetwork = "udp"
+	SyslogTCP SyslogNetwork = "tcp"
+	SyslogTLS SyslogNetwork = "tls"
+)
+
+// SyslogConfig sets where and how a SyslogForwarder sends.  Formatter renders the message
+// part of each syslog line and defaults to JSON.  A Facility of 0 uses local0.
+//
+// After a failure Run waits ReconnectDelay, doubling the wait on each further failure up to
+// MaxBackoff.  Failing to connect is retried for as long as it takes; a message that fails to
+// write on an open connection is retried MaxRetries times and then dropped.  OnError, when set,
+// is called with every message that is dropped and the reason.
+type SyslogConfig struct {
+	Network        SyslogNetwork
+	Address        string
+	TLSConfig      *tls.Config
+	Format         SyslogFormat
+	Facility       int
+	Hostname       string
+	AppName        string
+	Formatter      Formatter
+	BufferSize     int
+	DialTimeout    time.Duration
+	ReconnectDelay time.Duration
+	MaxBackoff     time.Duration
+	MaxRetries     int
+	OnError        func(msg []byte, err error)
+}
+
+type SyslogForwarder struct {
+	cfg   SyslogConfig
+	queue chan syslogMessage
+	conn  net.Conn
+}
+
+// syslogMessage is a buffered message.  done, when set, receives nil once the message is
+// written or the error it was dropped with.
+type syslogMessage struct {
+	data []byte
+	done chan error
+}
+
+// NewSyslogForwarder checks cfg, fills in its defaults and returns a forwarder.  Nothing is
+// sent until Run is called.
+func NewSyslogForwarder(cfg SyslogConfig) (*SyslogForwarder, error) {
+
+	switch cfg.Network {
+	case SyslogUDP, SyslogTCP, SyslogTLS:
+	default:
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Network"}, Value: cfg.Network}
+	}
+	if cfg.Address == "" {
+		return nil, ErrMissingInput{Argument: "Address"}
+	}
+	switch cfg.Format {
+	case "":
+		cfg.Format = RFC5424
+	case RFC5424, RFC3164:
+	default:
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Format"}, Value: cfg.Format}
+	}
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return nil, ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "Facility"}, Value: cfg.Facility}
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = DefaultSyslogFacility
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = DefaultSyslogAppName
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = JSONLinesFormatter{}
+	}
+	if cfg.BufferSize < 1 {
+		cfg.BufferSize = DefaultSyslogBufferSize
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = DefaultSyslogDialTimeout
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = DefaultSyslogReconnectDelay
+	}
+	if cfg.MaxBackoff < cfg.ReconnectDelay {
+		cfg.MaxBackoff = DefaultSyslogMaxBackoff
+		if cfg.MaxBackoff < cfg.ReconnectDelay {
+			cfg.MaxBackoff = cfg.ReconnectDelay
+		}
+	}
+	if cfg.MaxRetries < 1 {
+		cfg.MaxRetries = DefaultSyslogMaxRetries
+	}
+
+	return &SyslogForwarder{cfg: cfg, queue: make(chan syslogMessage, cfg.BufferSize)}, nil
+}
+
+// Send formats r and adds it to the buffer.  While the buffer is full it blocks until there is
+// room or ctx is done.
+func (f *SyslogForwarder) Send(ctx context.Context, r Record) error {
+	_, err := f.send(ctx, r, false)
+	return err
+}
+
+// send buffers r as Send does.  With ack set the returned channel receives the outcome of
+// writing the message.
+func (f *SyslogForwarder) send(ctx context.Context, r Record, ack bool) (<-chan error, error) {
+	data, err := f.message(r, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	msg := syslogMessage{data: data}
+	if ack {
+		msg.done = make(chan error, 1)
+	}
+
+	select {
+	case f.queue <- msg:
+		return msg.done, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Buffered returns the number of messages waiting to be sent.
+func (f *SyslogForwarder) Buffered() int {
+	return len(f.queue)
+}
+
+// Run sends buffered messages to the collector until ctx is done.  None are dropped while the
+// collector cannot be reached, but a message that fails to write MaxRetries times is dropped so
+// it does not hold up the rest.  When ctx is done the message being sent and those still
+// buffered are dropped.  Dropped messages are reported to OnError.
+func (f *SyslogForwarder) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	defer f.disconnect()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.dropBuffered(ctx.Err())
+			return ctx.Err()
+		case msg := <-f.queue:
+			if err := f.deliver(ctx, msg); err != nil && ctx.Err() != nil {
+				f.dropBuffered(ctx.Err())
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// deliver writes msg, reconnecting and backing off between tries.  It returns the error msg was
+// dropped with.
+func (f *SyslogForwarder) deliver(ctx context.Context, msg syslogMessage) error {
+	delay := f.cfg.ReconnectDelay
+	failed := 0
+	for {
+		var err error
+		if f.conn == nil {
+			err = f.connect(ctx)
+		}
+		if err == nil {
+			if err = f.write(msg.data); err == nil {
+				f.done(msg, nil)
+				return nil
+			}
+			failed++
+		}
+		f.disconnect()
+
+		if failed > f.cfg.MaxRetries {
+			f.done(msg, err)
+			return err
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			f.done(msg, err)
+			return err
+		}
+		if delay *= 2; delay > f.cfg.MaxBackoff {
+			delay = f.cfg.MaxBackoff
+		}
+	}
+}
+
+// done reports the outcome of sending msg.
+func (f *SyslogForwarder) done(msg syslogMessage, err error) {
+	if err != nil && f.cfg.OnError != nil {
+		f.cfg.OnError(msg.data, err)
+	}
+	if msg.done != nil {
+		msg.done <- err
+	}
+}
+
+// dropBuffered drops every buffered message with err.
+func (f *SyslogForwarder) dropBuffered(err error) {
+	for {
+		select {
+		case msg := <-f.queue:
+			f.done(msg, err)
+		default:
+			return
+		}
+	}
+}
+
+func (f *SyslogForwarder) write(msg []byte) error {
+
+	// stream transports are framed with octet counting (RFC 6587) for RFC 5424 and a
+	// trailing newline for RFC 3164, as collectors expect
+	frame := msg
+	switch {
+	case f.cfg.Network == SyslogUDP:
+	case f.cfg.Format == RFC5424:
+		frame = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	default:
+		frame = append(msg[:len(msg):len(msg)], '\n')
+	}
+
+	if err := f.conn.SetWriteDeadline(time.Now().Add(f.cfg.DialTimeout)); err != nil {
+		return err
+	}
+	_, err := f.conn.Write(frame)
+	return err
+}
+
+func (f *SyslogForwarder) connect(ctx context.Context) error {
+	d := &net.Dialer{Timeout: f.cfg.DialTimeout}
+
+	var conn net.Conn
+	var err error
+	if f.cfg.Network == SyslogTLS {
+		conn, err = d.DialContext(ctx, "tcp", f.cfg.Address)
+		if err == nil {
+			tc := tls.Client(conn, f.tlsConfig())
+			tc.SetDeadline(time.Now().Add(f.cfg.DialTimeout))
+			if err = tc.Handshake(); err != nil {
+				conn.Close()
+			}
+			tc.SetDeadline(time.Time{})
+			conn = tc
+		}
+	} else {
+		conn, err = d.DialContext(ctx, string(f.cfg.Network), f.cfg.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.conn = conn
+	return nil
+}
+
+func (f *SyslogForwarder) tlsConfig() *tls.Config {
+	if f.cfg.TLSConfig != nil && f.cfg.TLSConfig.ServerName != "" {
+		return f.cfg.TLSConfig
+	}
+	cfg := &tls.Config{}
+	if f.cfg.TLSConfig != nil {
+		cfg = f.cfg.TLSConfig.Clone()
+	}
+	cfg.ServerName, _, _ = net.SplitHostPort(f.cfg.Address)
+	return cfg
+}
+
+func (f *SyslogForwarder) disconnect() {
+	if f.conn == nil {
+		return
+	}
+	f.conn.Close()
+	f.conn = nil
+}
+
+// message renders r as a syslog line, without framing.
+func (f *SyslogForwarder) message(r Record, now time.Time) ([]byte, error) {
+	body, err := f.cfg.Formatter.Format(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := r.Time
+	if ts.IsZero() {
+		ts = now
+	}
+	pri := f.cfg.Facility*8 + syslogSeverity(r.Severity)
+
+	var header string
+	if f.cfg.Format == RFC3164 {
+		header = fmt.Sprintf("<%d>%s %s %s: ", pri, ts.Format(time.Stamp), syslogToken(f.cfg.Hostname, 255), syslogToken(f.cfg.AppName, 32))
+	} else {
+		msgID := r.Kind
+		if msgID == "" {
+			msgID = "-"
+		}
+		header = fmt.Sprintf("<%d>1 %s %s %s - %s - ", pri, ts.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogToken(f.cfg.Hostname, 255), syslogToken(f.cfg.AppName, 48), syslogToken(msgID, 32))
+	}
+
+	return append([]byte(header), body...), nil
+}
+
+// syslogSeverity maps Sophos severities to syslog severities.
+func syslogSeverity(s Severity) int {
+	switch s {
+	case "critical", "very_high":
+		return 2 // critical
+	case High:
+		return 3 // error
+	case Medium:
+		return 4 // warning
+	case Low:
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// syslogToken makes s fit a syslog header field: printable ascii without spaces, no longer
+// than max, and "-" when empty.
+func syslogToken(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// ForwardAlerts polls the alerts of a tenant every interval and sends each new alert to f,
+// starting with alerts raised since from.  An alert whose raisedAt cannot be read is sent
+// once and logged; it does not move the polling position.  It runs until ctx is done or a
+// poll fails.
+func (c *Client) ForwardAlerts(ctx context.Context, tenant TenantsResponseItem, f *SyslogForwarder, from time.Time, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultForwardPollInterval
+	}
+
+	// alerts raised at exactly from come back on the next poll, so they are remembered
+	seen := map[string]bool{}
+	// alerts with an unreadable raisedAt are remembered for as long as this runs
+	badTime := map[string]bool{}
+	for {
+		latest := from
+		next := map[string]bool{}
+
+		qp := map[string]string{"from": from.UTC().Format("2006-01-02T15:04:05.000Z"), "sort": "raisedAt:asc"}
+		for {
+			if err := c.waitForRateLimit(ctx); err != nil {
+				return err
+			}
+			alerts, err := c.GetAlerts(ctx, tenant, qp)
+			if err != nil {
+				return err
+			}
+
+			for _, a := range alerts.Items {
+				raised, err := time.Parse(time.RFC3339, a.RaisedAt)
+				if err != nil {
+					if badTime[a.ID] {
+						continue
+					}
+					badTime[a.ID] = true
+					if c.logger != nil {
+						c.logger.WithFields(logrus.Fields{
+							"tenantID": tenant.ID,
+							"alertID":  a.ID,
+							"raisedAt": a.RaisedAt,
+						}).Warn("alert raisedAt cannot be parsed")
+					}
+					if err := f.Send(ctx, AlertItemRecord(a)); err != nil {
+						return err
+					}
+					continue
+				}
+				if raised.After(latest) {
+					latest = raised
+					next = map[string]bool{}
+				}
+				if raised.Equal(latest) {
+					next[a.ID] = true
+				}
+				if seen[a.ID] {
+					continue
+				}
+				if err := f.Send(ctx, AlertItemRecord(a)); err != nil {
+					return err
+				}
+			}
+
+			if alerts.Pages.NextKey == "" {
+				break
+			}
+			qp["pageFromKey"] = alerts.Pages.NextKey
+		}
+
+		if latest.After(from) {
+			seen = next
+		} else {
+			for id := range next {
+				seen[id] = true
+			}
+		}
+		from = latest
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// ForwardSIEMEvents polls the SIEM events of a tenant every interval and sends each event to f,
+// keeping its place in store as EachSIEMEvent does.  The cursor after a page is saved only once
+// every event of the page has been written to the collector, so events still buffered when the
+// process stops are read again on the next start.  f must be running.  It runs until ctx is
+// done, a poll fails or f drops an event.
+func (c *Client) ForwardSIEMEvents(ctx context.Context, tenant TenantsResponseItem, q SIEMQuery, store SIEMCursorStore, f *SyslogForwarder, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultForwardPollInterval
+	}
+	if store == nil {
+		store = NewMemoryCursorStore()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	acked := &ackCursorStore{SIEMCursorStore: store, ctx: ctx}
+	for {
+		err := c.EachSIEMEvent(ctx, tenant, q, acked, func(e SIEMEvent) error {
+			ack, err := f.send(ctx, SIEMEventRecord(e), true)
+			if err != nil {
+				return err
+			}
+			acked.pending = append(acked.pending, ack)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// later polls carry on from the stored cursor
+		q.Cursor = ""
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// ackCursorStore holds back saving a cursor until every message sent before it has been written
+// to the collector.
+type ackCursorStore struct {
+	SIEMCursorStore
+	ctx     context.Context
+	pending []<-chan error
+}
+
+func (s *ackCursorStore) SaveCursor(key, cursor string) error {
+	for len(s.pending) > 0 {
+		select {
+		case err := <-s.pending[0]:
+			if err != nil {
+				return err
+			}
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		}
+		s.pending = s.pending[1:]
+	}
+	return s.SIEMCursorStore.SaveCursor(key, cursor)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
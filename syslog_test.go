@@ -0,0 +1,242 @@
+package sophoscentral
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestSyslogForwarder_message(t *testing.T) {
+
+	r := Record{Kind: "alert", Type: "xgFirewall", Severity: High, Time: mustParseTime("2021-05-05T11:47:30.148Z", time.RFC3339),
+		Fields: []Field{{Key: "description", Value: "Firewall down"}}}
+
+	tests := []struct {
+		name   string
+		format SyslogFormat
+		want   string
+	}{
+		{
+			name:   "rfc5424",
+			format: RFC5424,
+			want:   `<131>1 2021-05-05T11:47:30.148000Z collector01 sophos-central - alert - description="Firewall down"`,
+		},
+		{
+			name:   "rfc3164",
+			format: RFC3164,
+			want:   `<131>May  5 11:47:30 collector01 sophos-central: description="Firewall down"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogUDP, Address: "127.0.0.1:514", Format: tt.format, Hostname: "collector 01", Formatter: KeyValueFormatter{}})
+			a.NoError(err)
+			f.cfg.Hostname = "collector01"
+
+			got, err := f.message(r, time.Now())
+			a.NoError(err)
+			a.Equal(tt.want, string(got))
+		})
+	}
+}
+
+func TestSyslogForwarder_Run(t *testing.T) {
+	a := assert.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	a.NoError(err)
+	defer ln.Close()
+
+	f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogTCP, Address: ln.Addr().String(), Hostname: "h", BufferSize: 1,
+		Formatter: KeyValueFormatter{}, ReconnectDelay: time.Millisecond})
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// with nothing draining the buffer the second send waits for room
+	a.NoError(f.Send(ctx, Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e1"}}}))
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	a.Error(f.Send(short, Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e2"}}}))
+	cancelShort()
+	a.Equal(1, f.Buffered())
+
+	go f.Run(ctx)
+
+	conn, err := ln.Accept()
+	a.NoError(err)
+	defer conn.Close()
+
+	br := bufio.NewReader(conn)
+	length, err := br.ReadString(' ')
+	a.NoError(err)
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	a.NoError(err)
+	msg := make([]byte, n)
+	_, err = io.ReadFull(br, msg)
+	a.NoError(err)
+	a.Regexp(`^<134>1 \S+ h sophos-central - events - id="e1"$`, string(msg))
+}
+
+func TestRateLimiter_reserve(t *testing.T) {
+	a := assert.New(t)
+
+	l := newRateLimiter(2, 2)
+	now := mustParseTime("2021-05-05T11:47:30Z", time.RFC3339)
+	a.Zero(l.reserve(now))
+	a.Zero(l.reserve(now))
+	a.Equal(500*time.Millisecond, l.reserve(now))
+	a.Zero(l.reserve(now.Add(500 * time.Millisecond)))
+}
+
+func TestSyslogForwarder_Run_drops(t *testing.T) {
+	a := assert.New(t)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	a.NoError(err)
+	defer pc.Close()
+
+	var dropped [][]byte
+	var errs []error
+	f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogUDP, Address: pc.LocalAddr().String(), Hostname: "h",
+		Formatter: KeyValueFormatter{}, ReconnectDelay: time.Millisecond, MaxRetries: 2,
+		OnError: func(msg []byte, err error) {
+			dropped = append(dropped, msg)
+			errs = append(errs, err)
+		}})
+	a.NoError(err)
+
+	// too large for a datagram, so it can never be written
+	a.NoError(f.Send(context.Background(), Record{Kind: "events", Fields: []Field{{Key: "id", Value: strings.Repeat("x", 70000)}}}))
+	a.NoError(f.Send(context.Background(), Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e2"}}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- f.Run(ctx) }()
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	a.NoError(err)
+	a.Regexp(`id="e2"$`, string(buf[:n]))
+
+	cancel()
+	a.Equal(context.Canceled, <-done)
+	a.Len(dropped, 1)
+	a.Len(errs, 1)
+	a.Contains(string(dropped[0]), strings.Repeat("x", 100))
+}
+
+func TestSyslogForwarder_Run_cancelled(t *testing.T) {
+	a := assert.New(t)
+
+	var dropped []string
+	f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogTCP, Address: "127.0.0.1:1", Hostname: "h",
+		Formatter: KeyValueFormatter{}, ReconnectDelay: time.Millisecond,
+		OnError: func(msg []byte, err error) {
+			a.Equal(context.Canceled, err)
+			dropped = append(dropped, string(msg))
+		}})
+	a.NoError(err)
+
+	a.NoError(f.Send(context.Background(), Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e1"}}}))
+	a.NoError(f.Send(context.Background(), Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e2"}}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.Equal(context.Canceled, f.Run(ctx))
+	a.Len(dropped, 2)
+	a.Zero(f.Buffered())
+}
+
+func TestAckCursorStore_SaveCursor(t *testing.T) {
+	a := assert.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	a.NoError(err)
+	defer ln.Close()
+
+	f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogTCP, Address: ln.Addr().String(), Hostname: "h",
+		Formatter: KeyValueFormatter{}, ReconnectDelay: time.Millisecond})
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mem := NewMemoryCursorStore()
+	store := &ackCursorStore{SIEMCursorStore: mem, ctx: ctx}
+	ack, err := f.send(ctx, Record{Kind: "events", Fields: []Field{{Key: "id", Value: "e1"}}}, true)
+	a.NoError(err)
+	store.pending = append(store.pending, ack)
+
+	// nothing is written until Run, so the cursor is held back
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	store.ctx = short
+	a.Error(store.SaveCursor("k", "c1"))
+	cancelShort()
+	cursor, _ := mem.LoadCursor("k")
+	a.Empty(cursor)
+
+	store.ctx = ctx
+	go f.Run(ctx)
+	conn, err := ln.Accept()
+	a.NoError(err)
+	defer conn.Close()
+
+	a.NoError(store.SaveCursor("k", "c1"))
+	cursor, _ = mem.LoadCursor("k")
+	a.Equal("c1", cursor)
+}
+
+func TestClient_ForwardAlerts_badRaisedAt(t *testing.T) {
+	a := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	polls := 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			a.Equal("/common/v1/alerts", req.URL.Path)
+			polls++
+			if polls == 3 {
+				cancel()
+			}
+			body := `{"items": [
+				{"id": "bc893b97-86a8-41aa-b65c-910e11505605", "raisedAt": "yesterday"},
+				{"id": "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", "raisedAt": "2021-05-05T11:47:30.148Z"}
+			], "pages": {}}`
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+
+	f, err := NewSyslogForwarder(SyslogConfig{Network: SyslogUDP, Address: "127.0.0.1:514", Hostname: "h", Formatter: KeyValueFormatter{}})
+	a.NoError(err)
+
+	from := mustParseTime("2021-05-05T11:00:00Z", time.RFC3339)
+	a.Error(c.ForwardAlerts(ctx, tenant, f, from, time.Millisecond))
+	a.GreaterOrEqual(polls, 3)
+
+	// each alert is sent once however often it comes back
+	a.Equal(2, f.Buffered())
+	for i := 0; i < 2; i++ {
+		msg := <-f.queue
+		a.Contains(string(msg.data), []string{"bc893b97-86a8-41aa-b65c-910e11505605", "d2ba043d-7fcd-4158-a861-1ec2c01f3d14"}[i])
+	}
+}
@@ -0,0 +1,214 @@
+package sophoscentral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/*
+
+Implementation for sophos central XDR QUERY API
+https://developer.sophos.com/docs/xdr-query-v1/1/overview
+
+GET		/queries/runs
+POST	/queries/runs
+GET		/queries/runs/{runId}
+POST	/queries/runs/{runId}/cancel
+GET		/queries/runs/{runId}/results
+
+Queries run against the data lake, which keeps 30 days of endpoint and server telemetry.
+*/
+
+// CreateXDRQueryRun starts a data lake query over the time range of xrr.
+func (c *Client) CreateXDRQueryRun(ctx context.Context, tenant TenantsResponseItem, xrr XDRQueryRunRequest) (XDRQueryRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/xdr-query/v1/queries/runs
+
+	if err := xrr.validate(); err != nil {
+		return XDRQueryRun{}, err
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", "/xdr-query/v1/queries/runs", nil, xrr)
+	if err != nil {
+		return XDRQueryRun{}, err
+	}
+
+	return UnmarshalXDRQueryRun(b)
+}
+
+// GetXDRQueryRuns returns the data lake query runs of a tenant.
+// Allowed query params: createdAt, finishedAt, queryId, status, result, sort
+func (c *Client) GetXDRQueryRuns(ctx context.Context, tenant TenantsResponseItem, queryParams map[string]string) (XDRQueryRuns, error) {
+	// https://api-{dataRegion}.central.sophos.com/xdr-query/v1/queries/runs
+
+	var all XDRQueryRuns
+	err := c.tenantPages(ctx, tenant, "/xdr-query/v1/queries/runs", queryParams, func(b []byte) (Pages, error) {
+		xr, err := UnmarshalXDRQueryRuns(b)
+		if err != nil {
+			return Pages{}, err
+		}
+		all.Items = append(all.Items, xr.Items...)
+		all.Pages = xr.Pages
+		return xr.Pages, nil
+	})
+	if err != nil {
+		return XDRQueryRuns{}, err
+	}
+
+	return all, nil
+}
+
+// GetXDRQueryRun returns the status of a data lake query run.
+func (c *Client) GetXDRQueryRun(ctx context.Context, tenant TenantsResponseItem, runID string) (XDRQueryRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/xdr-query/v1/queries/runs/{runId}
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return XDRQueryRun{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "GET", fmt.Sprintf("/xdr-query/v1/queries/runs/%s", runID), nil, nil)
+	if err != nil {
+		return XDRQueryRun{}, err
+	}
+
+	return UnmarshalXDRQueryRun(b)
+}
+
+// CancelXDRQueryRun stops a data lake query run.
+func (c *Client) CancelXDRQueryRun(ctx context.Context, tenant TenantsResponseItem, runID string) (XDRQueryRun, error) {
+	// https://api-{dataRegion}.central.sophos.com/xdr-query/v1/queries/runs/{runId}/cancel
+
+	if _, err := uuid.Parse(runID); err != nil {
+		return XDRQueryRun{}, fmt.Errorf("%s: %w", ErrRunID, err)
+	}
+
+	b, err := c.tenantRequest(ctx, tenant, "POST", fmt.Sprintf("/xdr-query/v1/queries/runs/%s/cancel", runID), nil, struct{}{})
+	if err != nil {
+		return XDRQueryRun{}, err
+	}
+
+	return UnmarshalXDRQueryRun(b)
+}
+
+// WaitForXDRQueryRun polls a data lake query run every pollInterval until it has finished.  The
+// last status seen is returned along with any error, including when ctx is done.  A run that
+// is no longer wanted when ctx is done is not cancelled; use CancelXDRQueryRun.
+func (c *Client) WaitForXDRQueryRun(ctx context.Context, tenant TenantsResponseItem, runID string, pollInterval time.Duration) (XDRQueryRun, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultQueryPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		run, err := c.GetXDRQueryRun(ctx, tenant, runID)
+		if err != nil {
+			return run, err
+		}
+		if run.Status == QueryRunFinished {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// XDRQueryResults returns an iterator over the rows of a finished data lake query run, reading
+// pageSize rows at a time.  A pageSize of 0 uses the api default.
+func (c *Client) XDRQueryResults(ctx context.Context, tenant TenantsResponseItem, runID string, pageSize int) *QueryResultIterator {
+	// https://api-{dataRegion}.central.sophos.com/xdr-query/v1/queries/runs/{runId}/results
+
+	var err error
+	if _, perr := uuid.Parse(runID); perr != nil {
+		err = fmt.Errorf("%s: %w", ErrRunID, perr)
+	}
+
+	return c.newQueryResultIterator(ctx, tenant, fmt.Sprintf("/xdr-query/v1/queries/runs/%s/results", runID), pageSize, err)
+}
+
+func (xrr XDRQueryRunRequest) validate() error {
+	switch {
+	case xrr.SavedQuery == nil && xrr.AdHocQuery == nil:
+		return ErrMissingInput{Argument: "SavedQuery or AdHocQuery"}
+	case xrr.SavedQuery != nil && xrr.AdHocQuery != nil:
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "AdHocQuery"}, Value: "only one of SavedQuery and AdHocQuery can be set"}
+	case xrr.SavedQuery != nil:
+		if _, err := uuid.Parse(xrr.SavedQuery.QueryID); err != nil {
+			return fmt.Errorf("%s: %w", ErrQueryID, err)
+		}
+	case xrr.AdHocQuery.Template == "":
+		return ErrMissingInput{Argument: "AdHocQuery.Template"}
+	}
+
+	if xrr.From.IsZero() {
+		return ErrMissingInput{Argument: "From"}
+	}
+	if xrr.To.IsZero() {
+		return ErrMissingInput{Argument: "To"}
+	}
+	if !xrr.From.Before(xrr.To) {
+		return ErrInvalidInput{ErrMissingInput: ErrMissingInput{Argument: "From"}, Value: xrr.From}
+	}
+	return nil
+}
+
+func UnmarshalXDRQueryRuns(data []byte) (XDRQueryRuns, error) {
+	var r XDRQueryRuns
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return XDRQueryRuns{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+func UnmarshalXDRQueryRun(data []byte) (XDRQueryRun, error) {
+	var r XDRQueryRun
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return XDRQueryRun{}, fmt.Errorf("%s: %w", ErrUnmarshalFailed, err)
+	}
+	return r, nil
+}
+
+// XDRQueryRunRequest starts a data lake query.  Exactly one of SavedQuery and AdHocQuery is
+// set, and the query reads the data between From and To.
+type XDRQueryRunRequest struct {
+	AdHocQuery     *AdHocQuery     `json:"adHocQuery,omitempty"`
+	SavedQuery     *SavedQueryRef  `json:"savedQuery,omitempty"`
+	Variables      []QueryVariable `json:"variables,omitempty"`
+	MatchEndpoints *MatchEndpoints `json:"matchEndpoints,omitempty"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+}
+
+type XDRQueryRuns struct {
+	Items []XDRQueryRun `json:"items"`
+	Pages Pages         `json:"pages"`
+}
+
+type XDRQueryRun struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name,omitempty"`
+	QueryID    string         `json:"queryId,omitempty"`
+	Template   string         `json:"template,omitempty"`
+	Status     QueryRunStatus `json:"status"`
+	Result     QueryRunResult `json:"result"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	CreatedBy  ItemCreatedBy  `json:"createdBy"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
+}
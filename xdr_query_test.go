@@ -0,0 +1,70 @@
+package sophoscentral
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_XDRQueryResults(t *testing.T) {
+	a := assert.New(t)
+
+	tenant := TenantsResponseItem{ID: "49310a33-4acc-409b-aafb-07b8bc06ef01", ApiHost: "https://api-us03.central.sophos.com"}
+	pages := map[string]string{
+		"":  `{"items": [{"name": "a", "count": 1}, {"name": "b", "count": 2}], "metadata": {"columns": [{"name": "name", "type": "text"}, {"name": "count", "type": "integer"}]}, "pages": {"current": 1, "total": 2}}`,
+		"2": `{"items": [{"name": "c", "count": 3}], "pages": {"current": 2, "total": 2}}`,
+	}
+
+	requests := 0
+	hc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requests++
+			a.Equal("/xdr-query/v1/queries/runs/d2ba043d-7fcd-4158-a861-1ec2c01f3d14/results", req.URL.Path)
+			a.Equal("2", req.URL.Query().Get("pageSize"))
+			body := pages[req.URL.Query().Get("page")]
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+		}),
+	}
+	c := &Client{logger: logrus.New(), token: &oauth2.Token{AccessToken: "access token"}, httpClient: hc}
+
+	it := c.XDRQueryResults(context.Background(), tenant, "d2ba043d-7fcd-4158-a861-1ec2c01f3d14", 2)
+	var names []string
+	var total int64
+	for it.Next() {
+		s, _ := it.Row().String("name")
+		names = append(names, s)
+		v, err := it.Value("count")
+		a.NoError(err)
+		total += v.(int64)
+		if len(names) == 1 {
+			// the second page is only read once the first is used up
+			a.Equal(1, requests)
+		}
+	}
+	a.NoError(it.Err())
+	a.Equal([]string{"a", "b", "c"}, names)
+	a.Equal(int64(6), total)
+	a.Equal(2, requests)
+	a.Len(it.Metadata().Columns, 2)
+
+	it = c.XDRQueryResults(context.Background(), tenant, "not a run id", 0)
+	a.False(it.Next())
+	a.Error(it.Err())
+}
+
+func TestXDRQueryRunRequest_validate(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now()
+	a.NoError(XDRQueryRunRequest{AdHocQuery: &AdHocQuery{Template: "SELECT 1"}, From: now.Add(-30 * 24 * time.Hour), To: now}.validate())
+	a.Error(XDRQueryRunRequest{AdHocQuery: &AdHocQuery{Template: "SELECT 1"}, From: now, To: now.Add(-time.Hour)}.validate())
+	a.Error(XDRQueryRunRequest{AdHocQuery: &AdHocQuery{Template: "SELECT 1"}, To: now}.validate())
+	a.Error(XDRQueryRunRequest{SavedQuery: &SavedQueryRef{QueryID: "x"}, From: now.Add(-time.Hour), To: now}.validate())
+}